@@ -2,29 +2,78 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
-	"github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/kubernetes/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
 )
 
+//Scheme holds the types of this group version, as served by the API server. The kind of a Workflow is
+//registered as in deployments/crd.yaml as well, so objects returned by the API server can be decoded.
+var Scheme = runtime.NewScheme()
+
+//Codecs and ParameterCodec encode and decode objects and options of this group version
+var (
+	Codecs         = serializer.NewCodecFactory(Scheme)
+	ParameterCodec = runtime.NewParameterCodec(Scheme)
+)
+
+func init() {
+	//types are registered here rather than through SchemeBuilder, whose types are only registered by a later init
+	Scheme.AddKnownTypes(GroupVersion, &Workflow{}, &WorkflowList{}, &WorkflowRun{}, &WorkflowRunList{})
+	metav1.AddToGroupVersion(Scheme, GroupVersion)
+	Scheme.AddKnownTypeWithName(GroupVersion.WithKind(Kind), &Workflow{})
+	Scheme.AddKnownTypeWithName(GroupVersion.WithKind("WorkFlowList"), &WorkflowList{})
+}
+
+// +kubebuilder:object:generate=false
 type WorkFlowV1Interface interface {
+	RESTClient() rest.Interface
 	WorkFlows(namespace string) WorkFlowInterface
+	WorkFlowRuns(namespace string) WorkFlowRunInterface
 }
 
+// +kubebuilder:object:generate=false
 type WorkFlowClient struct {
 	restClient rest.Interface
 }
 
+// +kubebuilder:object:generate=false
 type WorkFlowInterface interface {
-	List() (*WorkflowList, error)
-	Get(name string) (*Workflow, error)
-	Put(name string, workflow *Workflow) (*Workflow, error)
-	//Create(*v1alpha1.Project) (*v1alpha1.Project, error)
-	//Watch(opts metav1.ListOptions) (watch.Interface, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*WorkflowList, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*Workflow, error)
+	Create(ctx context.Context, workflow *Workflow, opts metav1.CreateOptions) (*Workflow, error)
+	Update(ctx context.Context, workflow *Workflow, opts metav1.UpdateOptions) (*Workflow, error)
+	UpdateStatus(ctx context.Context, workflow *Workflow, opts metav1.UpdateOptions) (*Workflow, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*Workflow, error)
+	ApplyStatus(ctx context.Context, workflow *Workflow, fieldManager string) (*Workflow, error)
+}
+
+// +kubebuilder:object:generate=false
+type WorkFlowRunInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*WorkflowRunList, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*WorkflowRun, error)
+	Create(ctx context.Context, run *WorkflowRun, opts metav1.CreateOptions) (*WorkflowRun, error)
+	Update(ctx context.Context, run *WorkflowRun, opts metav1.UpdateOptions) (*WorkflowRun, error)
+	UpdateStatus(ctx context.Context, run *WorkflowRun, opts metav1.UpdateOptions) (*WorkflowRun, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*WorkflowRun, error)
+}
+
+//RunsOf returns list options selecting the runs of a workflow
+func RunsOf(workflow string) metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: "workflow=" + workflow}
 }
 
+// +kubebuilder:object:generate=false
 type workflowclient struct {
 	restClient rest.Interface
 	ns         string
@@ -32,19 +81,31 @@ type workflowclient struct {
 
 func NewForConfig(config *rest.Config) (*WorkFlowClient, error) {
 	crdConfig := *config
-	crdConfig.ContentConfig.GroupVersion = &schema.GroupVersion{Group: "trinity.cloudlego.com", Version: "v1"}
+	crdConfig.ContentConfig.GroupVersion = &GroupVersion
 	crdConfig.APIPath = "/apis"
-	crdConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
-	crdConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+	crdConfig.NegotiatedSerializer = Codecs.WithoutConversion()
+	if crdConfig.UserAgent == "" {
+		crdConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
 
 	client, err := rest.RESTClientFor(&crdConfig)
 	if err != nil {
-		logrus.Fatalf("Error occured while creating workflow client:%v", err)
+		return nil, err
 	}
 
 	return &WorkFlowClient{restClient: client}, nil
 }
 
+//New creates a WorkFlowClient for the given RESTClient
+func New(c rest.Interface) *WorkFlowClient {
+	return &WorkFlowClient{restClient: c}
+}
+
+//RESTClient returns the RESTClient used to talk to the API server
+func (c *WorkFlowClient) RESTClient() rest.Interface {
+	return c.restClient
+}
+
 func (c *WorkFlowClient) WorkFlows(namespace string) WorkFlowInterface {
 	return &workflowclient{
 		restClient: c.restClient,
@@ -52,45 +113,287 @@ func (c *WorkFlowClient) WorkFlows(namespace string) WorkFlowInterface {
 	}
 }
 
-func (c *workflowclient) List() (*WorkflowList, error) {
+func (c *WorkFlowClient) WorkFlowRuns(namespace string) WorkFlowRunInterface {
+	return &workflowrunclient{
+		restClient: c.restClient,
+		ns:         namespace,
+	}
+}
+
+//List returns the workflows in the namespace, or in all namespaces when the client was created for an empty namespace
+func (c *workflowclient) List(ctx context.Context, opts metav1.ListOptions) (*WorkflowList, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
 	result := WorkflowList{}
 	err := c.restClient.
 		Get().
 		Namespace(c.ns).
 		Resource("workflows").
-		//VersionedParams(&opts, scheme.ParameterCodec).
-		Do(context.Background()).
+		VersionedParams(&opts, ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
 		Into(&result)
 
 	return &result, err
 }
 
-func (c *workflowclient) Get(name string) (*Workflow, error) {
+func (c *workflowclient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*Workflow, error) {
 	result := Workflow{}
 	err := c.restClient.
 		Get().
 		Namespace(c.ns).
 		Resource("workflows").
 		Name(name).
-		SubResource("status").
-		//VersionedParams(&opts, scheme.ParameterCodec).
-		Do(context.Background()).
+		VersionedParams(&opts, ParameterCodec).
+		Do(ctx).
 		Into(&result)
 
 	return &result, err
 }
 
-func (c *workflowclient) Put(name string, workflow *Workflow) (*Workflow, error) {
+func (c *workflowclient) Create(ctx context.Context, workflow *Workflow, opts metav1.CreateOptions) (*Workflow, error) {
+	result := Workflow{}
+	err := c.restClient.
+		Post().
+		Namespace(c.ns).
+		Resource("workflows").
+		VersionedParams(&opts, ParameterCodec).
+		Body(workflow).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+//Update replaces the spec and metadata of a workflow. Changes to the status are ignored.
+func (c *workflowclient) Update(ctx context.Context, workflow *Workflow, opts metav1.UpdateOptions) (*Workflow, error) {
 	result := Workflow{}
 	err := c.restClient.
 		Put().
 		Namespace(c.ns).
 		Resource("workflows").
-		Name(name).
+		Name(workflow.Name).
+		VersionedParams(&opts, ParameterCodec).
+		Body(workflow).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+//UpdateStatus replaces the status of a workflow. Changes to the spec and metadata are ignored.
+func (c *workflowclient) UpdateStatus(ctx context.Context, workflow *Workflow, opts metav1.UpdateOptions) (*Workflow, error) {
+	result := Workflow{}
+	err := c.restClient.
+		Put().
+		Namespace(c.ns).
+		Resource("workflows").
+		Name(workflow.Name).
 		SubResource("status").
+		VersionedParams(&opts, ParameterCodec).
 		Body(workflow).
-		//VersionedParams(&opts, scheme.ParameterCodec).
-		Do(context.Background()).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *workflowclient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.restClient.
+		Delete().
+		Namespace(c.ns).
+		Resource("workflows").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+func (c *workflowclient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("workflows").
+		VersionedParams(&opts, ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+//Patch applies a patch of type pt to a workflow or to one of its subresources
+func (c *workflowclient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*Workflow, error) {
+	result := Workflow{}
+	err := c.restClient.
+		Patch(pt).
+		Namespace(c.ns).
+		Resource("workflows").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+//ApplyStatus sets the status fields owned by fieldManager with a server-side apply. Only the fields set
+//in the status of workflow are applied, so fields written by other managers are left untouched and no
+//resourceVersion is needed. Fields fieldManager applied before and no longer sets are removed.
+func (c *workflowclient) ApplyStatus(ctx context.Context, workflow *Workflow, fieldManager string) (*Workflow, error) {
+	applied := struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Metadata   metav1.ObjectMeta `json:"metadata"`
+		Status     WorkflowStatus    `json:"status"`
+	}{
+		APIVersion: "trinity.cloudlego.com/v1",
+		Kind:       Kind,
+		Metadata: metav1.ObjectMeta{
+			Name:      workflow.Name,
+			Namespace: c.ns,
+		},
+		Status: workflow.Status,
+	}
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return nil, err
+	}
+
+	force := true
+	return c.Patch(ctx, workflow.Name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force}, "status")
+}
+
+// +kubebuilder:object:generate=false
+type workflowrunclient struct {
+	restClient rest.Interface
+	ns         string
+}
+
+//List returns the runs in the namespace. Use RunsOf to list the runs of a single workflow.
+func (c *workflowrunclient) List(ctx context.Context, opts metav1.ListOptions) (*WorkflowRunList, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result := WorkflowRunList{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		VersionedParams(&opts, ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *workflowrunclient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*WorkflowRun, error) {
+	result := WorkflowRun{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		Name(name).
+		VersionedParams(&opts, ParameterCodec).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *workflowrunclient) Create(ctx context.Context, run *WorkflowRun, opts metav1.CreateOptions) (*WorkflowRun, error) {
+	result := WorkflowRun{}
+	err := c.restClient.
+		Post().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		VersionedParams(&opts, ParameterCodec).
+		Body(run).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+//Update replaces the spec and metadata of a run. Changes to the status are ignored.
+func (c *workflowrunclient) Update(ctx context.Context, run *WorkflowRun, opts metav1.UpdateOptions) (*WorkflowRun, error) {
+	result := WorkflowRun{}
+	err := c.restClient.
+		Put().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		Name(run.Name).
+		VersionedParams(&opts, ParameterCodec).
+		Body(run).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+//UpdateStatus replaces the status of a run. Changes to the spec and metadata are ignored.
+func (c *workflowrunclient) UpdateStatus(ctx context.Context, run *WorkflowRun, opts metav1.UpdateOptions) (*WorkflowRun, error) {
+	result := WorkflowRun{}
+	err := c.restClient.
+		Put().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		Name(run.Name).
+		SubResource("status").
+		VersionedParams(&opts, ParameterCodec).
+		Body(run).
+		Do(ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *workflowrunclient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.restClient.
+		Delete().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+func (c *workflowrunclient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("workflowruns").
+		VersionedParams(&opts, ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+//Patch applies a patch of type pt to a run or to one of its subresources
+func (c *workflowrunclient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*WorkflowRun, error) {
+	result := WorkflowRun{}
+	err := c.restClient.
+		Patch(pt).
+		Namespace(c.ns).
+		Resource("workflowruns").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, ParameterCodec).
+		Body(data).
+		Do(ctx).
 		Into(&result)
 
 	return &result, err
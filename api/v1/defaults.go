@@ -0,0 +1,16 @@
+package v1
+
+// DefaultSchedule is the schedule of a workflow that does not set one, as defaulted by deployments/crd.yaml
+const DefaultSchedule = "*/5 * * * *"
+
+// SetDefaults sets the fields of a workflow that are left empty to their defaults. The defaulting webhook
+// applies them when a workflow is created or updated, so the defaults are visible on the stored object.
+func SetDefaults(wf *Workflow) {
+	if wf.Spec.Schedule == "" {
+		wf.Spec.Schedule = DefaultSchedule
+	}
+	if wf.Spec.OutputLimit == nil {
+		limit := int32(DefaultOutputLimit)
+		wf.Spec.OutputLimit = &limit
+	}
+}
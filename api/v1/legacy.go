@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// legacyTimestampLayout is the layout older versions used for the timestamps of a run
+const legacyTimestampLayout = "01-02-2006 15:04:05"
+
+// UnmarshalJSON accepts run timestamps written by older versions in legacyTimestampLayout and maps
+// their run and task phases to RunPhase and TaskPhase
+func (r *Workflowruns) UnmarshalJSON(data []byte) error {
+	type plain Workflowruns
+	var in struct {
+		plain
+		StartedAt json.RawMessage `json:"started_at"`
+		EndedAt   json.RawMessage `json:"ended_at"`
+	}
+	err := json.Unmarshal(data, &in)
+	if err != nil {
+		return err
+	}
+
+	*r = Workflowruns(in.plain)
+	r.StartedAt, err = unmarshalTime(in.StartedAt)
+	if err != nil {
+		return err
+	}
+	r.EndedAt, err = unmarshalTime(in.EndedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range r.Tasks {
+		r.Tasks[i].Status = legacyTaskPhase(r.Tasks[i].Status)
+	}
+	r.Phase = legacyRunPhase(r.Phase, r)
+	return nil
+}
+
+// UnmarshalJSON accepts run timestamps written by older versions in legacyTimestampLayout
+// and maps their phase to a RunPhase
+func (s *RunSummary) UnmarshalJSON(data []byte) error {
+	type plain RunSummary
+	var in struct {
+		plain
+		StartedAt json.RawMessage `json:"started_at"`
+		EndedAt   json.RawMessage `json:"ended_at"`
+	}
+	err := json.Unmarshal(data, &in)
+	if err != nil {
+		return err
+	}
+
+	*s = RunSummary(in.plain)
+	s.StartedAt, err = unmarshalTime(in.StartedAt)
+	if err != nil {
+		return err
+	}
+	s.EndedAt, err = unmarshalTime(in.EndedAt)
+	s.Phase = legacyRunPhase(s.Phase, nil)
+	return err
+}
+
+// unmarshalTime decodes an RFC 3339 or a legacy timestamp. Empty and null values decode to nil.
+func unmarshalTime(raw json.RawMessage) (*metav1.Time, error) {
+	var value string
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(legacyTimestampLayout, value, time.Local)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mt := metav1.NewTime(t)
+	return &mt, nil
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalLegacyRun(t *testing.T) {
+	data := `{"id":1,"phase":"completed","started_at":"03-01-2021 10:00:00","ended_at":"03-01-2021 10:05:30",` +
+		`"tasks":[{"name":"build","status":"success"},{"name":"test","status":"failed"}]}`
+
+	var run Workflowruns
+	err := json.Unmarshal([]byte(data), &run)
+	if err != nil {
+		t.Fatalf("failed to decode legacy run: %v", err)
+	}
+
+	started := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.Local)
+	if run.StartedAt == nil || !run.StartedAt.Time.Equal(started) {
+		t.Errorf("got start %v, want %v", run.StartedAt, started)
+	}
+	if run.EndedAt == nil || run.EndedAt.Sub(run.StartedAt.Time) != 5*time.Minute+30*time.Second {
+		t.Errorf("got end %v, want 5m30s after the start", run.EndedAt)
+	}
+	if run.Tasks[0].Status != TaskSucceeded || run.Tasks[1].Status != TaskFailed {
+		t.Errorf("got task phases %q and %q, want %q and %q", run.Tasks[0].Status, run.Tasks[1].Status, TaskSucceeded, TaskFailed)
+	}
+	if run.Phase != RunFailed {
+		t.Errorf("got phase %q, want %q from the failed task", run.Phase, RunFailed)
+	}
+}
+
+func TestUnmarshalLegacyPhases(t *testing.T) {
+	tests := []struct {
+		phase string
+		tasks string
+		want  RunPhase
+	}{
+		{"completed", `[{"status":"success"},{"status":"success"}]`, RunSucceeded},
+		{"completed", `[{"status":"success"},{"status":"failed"}]`, RunFailed},
+		{"completed", `[]`, RunSucceeded},
+		{"running", `[{"status":"success"}]`, RunRunning},
+		{"Running", `[]`, RunRunning},
+		{"Succeeded", `[{"status":"Failed"}]`, RunSucceeded},
+	}
+	for _, tt := range tests {
+		var run Workflowruns
+		err := json.Unmarshal([]byte(`{"id":1,"phase":"`+tt.phase+`","tasks":`+tt.tasks+`}`), &run)
+		if err != nil {
+			t.Errorf("failed to decode run in phase %q: %v", tt.phase, err)
+			continue
+		}
+		if run.Phase != tt.want {
+			t.Errorf("got phase %q for %q with tasks %s, want %q", run.Phase, tt.phase, tt.tasks, tt.want)
+		}
+	}
+
+	var summary RunSummary
+	err := json.Unmarshal([]byte(`{"name":"wf1-run-1","id":1,"phase":"running","started_at":"03-01-2021 10:00:00"}`), &summary)
+	if err != nil {
+		t.Fatalf("failed to decode legacy run summary: %v", err)
+	}
+	if summary.Phase != RunRunning || summary.StartedAt == nil {
+		t.Errorf("got summary %+v, want a running run with a start", summary)
+	}
+}
+
+func TestUnmarshalRFC3339RoundTrip(t *testing.T) {
+	data := `{"id":2,"phase":"Succeeded","started_at":"2021-03-01T10:00:00Z","ended_at":"2021-03-01T10:05:30Z",` +
+		`"tasks":[{"name":"build","status":"Succeeded","output":"","error":""}]}`
+
+	var run Workflowruns
+	err := json.Unmarshal([]byte(data), &run)
+	if err != nil {
+		t.Fatalf("failed to decode run: %v", err)
+	}
+	out, err := json.Marshal(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != data {
+		t.Errorf("got %s, want %s", out, data)
+	}
+
+	summary := `{"name":"wf1-run-2","id":2,"phase":"Succeeded","started_at":"2021-03-01T10:00:00Z","ended_at":"2021-03-01T10:05:30Z"}`
+	var s RunSummary
+	err = json.Unmarshal([]byte(summary), &s)
+	if err != nil {
+		t.Fatalf("failed to decode run summary: %v", err)
+	}
+	out, err = json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != summary {
+		t.Errorf("got %s, want %s", out, summary)
+	}
+}
+
+func TestUnmarshalMalformedTimestamps(t *testing.T) {
+	for _, data := range []string{
+		`{"id":1,"phase":"completed","started_at":"yesterday","tasks":[]}`,
+		`{"id":1,"phase":"completed","ended_at":"2021-13-45T99:00:00Z","tasks":[]}`,
+		`{"id":1,"phase":"completed","started_at":"31-01-2021 10:00:00","tasks":[]}`,
+		`{"id":1,"phase":"completed","started_at":1614592800,"tasks":[]}`,
+	} {
+		var run Workflowruns
+		if err := json.Unmarshal([]byte(data), &run); err == nil {
+			t.Errorf("decoding %s succeeded with %+v, want an error", data, run)
+		}
+		var summary RunSummary
+		if err := json.Unmarshal([]byte(data), &summary); err == nil {
+			t.Errorf("decoding %s as a summary succeeded with %+v, want an error", data, summary)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunPhase is the lifecycle phase of a WorkflowRun
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed;Error;Cancelled;TimedOut
+type RunPhase string
+
+// Phases of a WorkflowRun
+const (
+	// RunPending is a run that was created but whose tasks have not started yet
+	RunPending RunPhase = "Pending"
+	// RunRunning is a run whose tasks are being executed
+	RunRunning RunPhase = "Running"
+	// RunSucceeded is a run whose tasks all succeeded
+	RunSucceeded RunPhase = "Succeeded"
+	// RunFailed is a run with a task that failed
+	RunFailed RunPhase = "Failed"
+	// RunError is a run that could not be carried out, e.g. because a task pod could not be started
+	RunError RunPhase = "Error"
+	// RunCancelled is a run that was stopped before it finished
+	RunCancelled RunPhase = "Cancelled"
+	// RunTimedOut is a run that did not finish in time
+	RunTimedOut RunPhase = "TimedOut"
+)
+
+// runTransitions lists the phases a run may move to from each phase. Final phases have no entry.
+var runTransitions = map[RunPhase][]RunPhase{
+	"":         {RunPending, RunRunning},
+	RunPending: {RunRunning, RunError, RunCancelled, RunTimedOut},
+	RunRunning: {RunSucceeded, RunFailed, RunError, RunCancelled, RunTimedOut},
+}
+
+// Final reports whether a run in phase p has finished
+func (p RunPhase) Final() bool {
+	switch p {
+	case RunSucceeded, RunFailed, RunError, RunCancelled, RunTimedOut:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether a run may move from phase p to next. Staying in the same phase is allowed.
+func (p RunPhase) CanTransitionTo(next RunPhase) bool {
+	if p == next {
+		return true
+	}
+	for _, allowed := range runTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPhase moves the run to phase next. It returns an error and leaves the run unchanged when the
+// transition is not allowed.
+func (r *Workflowruns) SetPhase(next RunPhase) error {
+	if !r.Phase.CanTransitionTo(next) {
+		return fmt.Errorf("invalid run phase transition from %q to %q", r.Phase, next)
+	}
+	r.Phase = next
+	return nil
+}
+
+// Outcome returns the final phase of a run from the phases of its tasks. The first task that did not
+// succeed decides the outcome.
+func (r *Workflowruns) Outcome() RunPhase {
+	for _, task := range r.Tasks {
+		switch task.Status {
+		case TaskSucceeded, TaskSkipped:
+			continue
+		case TaskFailed:
+			return RunFailed
+		default:
+			return RunError
+		}
+	}
+	return RunSucceeded
+}
+
+// TaskPhase is the lifecycle phase of a task within a run
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed;Error;Skipped
+type TaskPhase string
+
+// Phases of a task
+const (
+	// TaskPending is a task that has not started yet
+	TaskPending TaskPhase = "Pending"
+	// TaskRunning is a task whose pod is running
+	TaskRunning TaskPhase = "Running"
+	// TaskSucceeded is a task whose command or script exited with 0
+	TaskSucceeded TaskPhase = "Succeeded"
+	// TaskFailed is a task whose command or script exited with a non-zero code
+	TaskFailed TaskPhase = "Failed"
+	// TaskError is a task that could not be executed, e.g. because its script could not be read
+	TaskError TaskPhase = "Error"
+	// TaskSkipped is a task that was not executed
+	TaskSkipped TaskPhase = "Skipped"
+)
+
+// taskTransitions lists the phases a task may move to from each phase. Final phases have no entry.
+var taskTransitions = map[TaskPhase][]TaskPhase{
+	"":          {TaskPending, TaskRunning, TaskSucceeded, TaskFailed, TaskError, TaskSkipped},
+	TaskPending: {TaskRunning, TaskError, TaskSkipped},
+	TaskRunning: {TaskSucceeded, TaskFailed, TaskError},
+}
+
+// Final reports whether a task in phase p has finished
+func (p TaskPhase) Final() bool {
+	switch p {
+	case TaskSucceeded, TaskFailed, TaskError, TaskSkipped:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether a task may move from phase p to next. Staying in the same phase is allowed.
+func (p TaskPhase) CanTransitionTo(next TaskPhase) bool {
+	if p == next {
+		return true
+	}
+	for _, allowed := range taskTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPhase moves the task to phase next. It returns an error and leaves the task unchanged when the
+// transition is not allowed.
+func (t *TaskStatus) SetPhase(next TaskPhase) error {
+	if !t.Status.CanTransitionTo(next) {
+		return fmt.Errorf("invalid task phase transition from %q to %q", t.Status, next)
+	}
+	t.Status = next
+	return nil
+}
+
+// legacyRunPhase maps the phase of a run written by older versions to a RunPhase. Older versions
+// marked every finished run as completed, so the outcome is taken from its tasks. Without the tasks
+// of the run the phase is returned unchanged.
+func legacyRunPhase(phase RunPhase, run *Workflowruns) RunPhase {
+	switch strings.ToLower(string(phase)) {
+	case "running":
+		return RunRunning
+	case "completed":
+		if run != nil {
+			return run.Outcome()
+		}
+	}
+	return phase
+}
+
+// legacyTaskPhase maps the status of a task written by older versions to a TaskPhase
+func legacyTaskPhase(phase TaskPhase) TaskPhase {
+	switch phase {
+	case "success":
+		return TaskSucceeded
+	case "failed":
+		return TaskFailed
+	}
+	return phase
+}
@@ -0,0 +1,108 @@
+package v1
+
+import "testing"
+
+var runPhases = []RunPhase{"", RunPending, RunRunning, RunSucceeded, RunFailed, RunError, RunCancelled, RunTimedOut}
+
+var taskPhases = []TaskPhase{"", TaskPending, TaskRunning, TaskSucceeded, TaskFailed, TaskError, TaskSkipped}
+
+func TestRunPhaseTransitions(t *testing.T) {
+	allowed := map[RunPhase][]RunPhase{
+		"":         {RunPending, RunRunning},
+		RunPending: {RunRunning, RunError, RunCancelled, RunTimedOut},
+		RunRunning: {RunSucceeded, RunFailed, RunError, RunCancelled, RunTimedOut},
+	}
+
+	for _, from := range runPhases {
+		for _, to := range runPhases {
+			want := from == to
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("transition of a run from %q to %q: got allowed %v, want %v", from, to, got, want)
+			}
+
+			run := Workflowruns{Phase: from}
+			err := run.SetPhase(to)
+			if want && (err != nil || run.Phase != to) {
+				t.Errorf("SetPhase from %q to %q: got phase %q and error %v, want phase %q", from, to, run.Phase, err, to)
+			}
+			if !want && (err == nil || run.Phase != from) {
+				t.Errorf("SetPhase from %q to %q: got phase %q and error %v, want an error and phase %q", from, to, run.Phase, err, from)
+			}
+		}
+	}
+}
+
+func TestTaskPhaseTransitions(t *testing.T) {
+	allowed := map[TaskPhase][]TaskPhase{
+		"":          {TaskPending, TaskRunning, TaskSucceeded, TaskFailed, TaskError, TaskSkipped},
+		TaskPending: {TaskRunning, TaskError, TaskSkipped},
+		TaskRunning: {TaskSucceeded, TaskFailed, TaskError},
+	}
+
+	for _, from := range taskPhases {
+		for _, to := range taskPhases {
+			want := from == to
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("transition of a task from %q to %q: got allowed %v, want %v", from, to, got, want)
+			}
+
+			task := TaskStatus{Status: from}
+			err := task.SetPhase(to)
+			if want && (err != nil || task.Status != to) {
+				t.Errorf("SetPhase from %q to %q: got phase %q and error %v, want phase %q", from, to, task.Status, err, to)
+			}
+			if !want && (err == nil || task.Status != from) {
+				t.Errorf("SetPhase from %q to %q: got phase %q and error %v, want an error and phase %q", from, to, task.Status, err, from)
+			}
+		}
+	}
+}
+
+func TestFinalPhases(t *testing.T) {
+	for _, p := range runPhases {
+		want := p == RunSucceeded || p == RunFailed || p == RunError || p == RunCancelled || p == RunTimedOut
+		if p.Final() != want {
+			t.Errorf("run phase %q: got final %v, want %v", p, p.Final(), want)
+		}
+	}
+	for _, p := range taskPhases {
+		want := p == TaskSucceeded || p == TaskFailed || p == TaskError || p == TaskSkipped
+		if p.Final() != want {
+			t.Errorf("task phase %q: got final %v, want %v", p, p.Final(), want)
+		}
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []TaskPhase
+		want  RunPhase
+	}{
+		{"no tasks", nil, RunSucceeded},
+		{"all succeeded", []TaskPhase{TaskSucceeded, TaskSucceeded}, RunSucceeded},
+		{"skipped tasks do not fail the run", []TaskPhase{TaskSucceeded, TaskSkipped}, RunSucceeded},
+		{"a failed task fails the run", []TaskPhase{TaskSucceeded, TaskFailed}, RunFailed},
+		{"a task in error is an error", []TaskPhase{TaskError, TaskSucceeded}, RunError},
+		{"the first task that did not succeed decides", []TaskPhase{TaskFailed, TaskError}, RunFailed},
+		{"the first task in error decides", []TaskPhase{TaskError, TaskFailed}, RunError},
+		{"an unfinished task is an error", []TaskPhase{TaskSucceeded, TaskRunning}, RunError},
+	}
+	for _, tt := range tests {
+		run := Workflowruns{}
+		for _, p := range tt.tasks {
+			run.Tasks = append(run.Tasks, TaskStatus{Status: p})
+		}
+		if got := run.Outcome(); got != tt.want {
+			t.Errorf("%s: got outcome %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,3 +1,9 @@
+// Package v1 holds version v1 of the workflows API, the version workflows and runs are stored in.
+// deployments/crd.yaml and zz_generated.deepcopy.go are generated from the types and markers of this package
+// and api/v2 with go generate ./pkg/manifests.
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:Optional
+// +groupName=trinity.cloudlego.com
 package v1
 
 //This block registers the scheme properly
@@ -17,3 +23,7 @@ var (
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
 )
+
+// Kind is the kind workflows are registered with in deployments/crd.yaml, which differs from the name of
+// the Workflow type.
+const Kind = "WorkFlow"
@@ -1,53 +1,221 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DefaultOutputLimit is the number of bytes of a task's output kept in the run status when the workflow does not set OutputLimit
+const DefaultOutputLimit = 2048
+
 // WorkflowSpec defines the desired state of Workflow
 type WorkflowSpec struct {
-	Schedule       string         `json:"schedule"`
+	// +kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
+	// +kubebuilder:default="*/5 * * * *"
+	Schedule string `json:"schedule"`
+	// +kubebuilder:default=false
 	StoreArtifacts bool           `json:"storeartifacts"`
 	Tasks          []Workflowtask `json:"tasks"`
+
+	// Env, EnvFrom and SecretMounts are applied to every task. A task can override them.
+	Env          []corev1.EnvVar        `json:"env,omitempty"`
+	EnvFrom      []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	SecretMounts []SecretMount          `json:"secretMounts,omitempty"`
+
+	// PodDefaults applies to the runner, task and artifact store pods of the workflow
+	PodDefaults *PodOptions `json:"podDefaults,omitempty"`
+
+	// ServiceAccountName is the service account the runner and task pods run under.
+	// Defaults to trinity-runner, which the controller maintains in the workflow's namespace.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// OutputLimit is the number of bytes of a task's output kept in the run status. Larger outputs are
+	// truncated and offloaded to the artifact store when it is enabled. Defaults to DefaultOutputLimit.
+	// +kubebuilder:validation:Minimum=0
+	OutputLimit *int32 `json:"outputLimit,omitempty"`
+
+	// SuccessfulRunsHistoryLimit and FailedRunsHistoryLimit are the number of finished runs to keep.
+	// RunTTL is how long a finished run is kept. Runs are kept forever when these are not set.
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulRunsHistoryLimit *int32 `json:"successfulRunsHistoryLimit,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	FailedRunsHistoryLimit *int32           `json:"failedRunsHistoryLimit,omitempty"`
+	RunTTL                 *metav1.Duration `json:"runTTL,omitempty"`
+}
+
+// Hash returns a hex encoded sha256 of the spec, which changes whenever the spec does
+func (s *WorkflowSpec) Hash() string {
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 type Workflowtask struct {
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]*$`
 	Name string `json:"name"`
 	//Type    string   `json:"type"`
-	Command struct {
-		Inline struct {
-			Command string   `json:"command"`
-			Args    []string `json:"args"`
-		} `json:"inline"`
-
-		Script string `json:"script"`
-	} `json:"command"`
+	Command Command `json:"command"`
 	//Args []string `json:"args"`
+
+	Env          []corev1.EnvVar        `json:"env,omitempty"`
+	EnvFrom      []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	SecretMounts []SecretMount          `json:"secretMounts,omitempty"`
+
+	// PodOptions set on a task override the workflow PodDefaults for the task pod
+	PodOptions `json:",inline"`
+}
+
+// Command is what a task runs: one of an inline command, a script or a script from a ConfigMap or Secret
+type Command struct {
+	Inline     InlineCommand `json:"inline"`
+	Script     string        `json:"script"`
+	ScriptFrom *ScriptSource `json:"scriptFrom,omitempty"`
 }
 
-// WorkflowStatus defines the observed state of Workflow
+// InlineCommand is an executable run with arguments
+type InlineCommand struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// PodOptions holds the resources and scheduling constraints of a pod started for a workflow
+type PodOptions struct {
+	Resources                *corev1.ResourceRequirements `json:"resources,omitempty"`
+	NodeSelector             map[string]string            `json:"nodeSelector,omitempty"`
+	Tolerations              []corev1.Toleration          `json:"tolerations,omitempty"`
+	Affinity                 *corev1.Affinity             `json:"affinity,omitempty"`
+	PriorityClassName        string                       `json:"priorityClassName,omitempty"`
+	SecurityContext          *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+	ContainerSecurityContext *corev1.SecurityContext      `json:"containerSecurityContext,omitempty"`
+}
+
+// SecretMount mounts a Secret as a read-only volume in the task container
+type SecretMount struct {
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+	// +kubebuilder:validation:Required
+	MountPath string             `json:"mountPath"`
+	Items     []corev1.KeyToPath `json:"items,omitempty"`
+}
+
+// ScriptSource selects a script stored under a key of a ConfigMap or a Secret
+type ScriptSource struct {
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+// WorkflowStatus defines the observed state of Workflow.
+// The history of runs is kept in WorkflowRun objects owned by the workflow.
 type WorkflowStatus struct {
-	Runs []Workflowruns `json:"runs"`
+	// LastRun and the counters of runs are maintained by the runner, the other fields by the controller
+	LastRun       *RunSummary `json:"lastRun,omitempty"`
+	TotalRuns     int         `json:"totalRuns,omitempty"`
+	SucceededRuns int         `json:"succeededRuns,omitempty"`
+	FailedRuns    int         `json:"failedRuns,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the controller last acted on
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	LastScheduleTime   *metav1.Time       `json:"lastScheduleTime,omitempty"`
+	NextScheduleTime   *metav1.Time       `json:"nextScheduleTime,omitempty"`
+	LastSuccessfulTime *metav1.Time       `json:"lastSuccessfulTime,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// LegacyRuns holds runs recorded by versions that kept the run history on the workflow.
+	// The controller moves them to WorkflowRun objects.
+	LegacyRuns []Workflowruns `json:"runs,omitempty"`
+}
+
+// Condition types maintained on the status of a Workflow
+const (
+	// ConditionReady is true when the controller has set up everything the workflow needs to run
+	ConditionReady = "Ready"
+	// ConditionScheduled is true when a CronJob with the schedule of the workflow exists
+	ConditionScheduled = "Scheduled"
+	// ConditionLastRunSucceeded reflects the outcome of the last finished run
+	ConditionLastRunSucceeded = "LastRunSucceeded"
+)
+
+// RunSummary describes a run without its task statuses
+type RunSummary struct {
+	Name      string       `json:"name"`
+	ID        int          `json:"id"`
+	Phase     RunPhase     `json:"phase"`
+	StartedAt *metav1.Time `json:"started_at,omitempty"`
+	EndedAt   *metav1.Time `json:"ended_at,omitempty"`
 }
 
 type Workflowruns struct {
 	ID        int          `json:"id"`
-	Phase     string       `json:"phase"`
-	StartedAt string       `json:"started_at"`
-	EndedAt   string       `json:"ended_at"`
+	Phase     RunPhase     `json:"phase"`
+	StartedAt *metav1.Time `json:"started_at,omitempty"`
+	EndedAt   *metav1.Time `json:"ended_at,omitempty"`
 	Tasks     []TaskStatus `json:"tasks"`
 }
 
+// Finished reports whether the run has reached a final phase
+func (r *Workflowruns) Finished() bool {
+	return r.Phase.Final()
+}
+
+// Succeeded reports whether the run finished with every task succeeded
+func (r *Workflowruns) Succeeded() bool {
+	return r.Phase == RunSucceeded
+}
+
 type TaskStatus struct {
 	Name string `json:"name"`
 	//Command string   `json:"command"`
 	//Args    []string `json:"args"`
-	Status string `json:"status"`
-	Output string `json:"output"`
-	Error  string `json:"error"`
+	Status TaskPhase  `json:"status"`
+	Output string     `json:"output"`
+	Stderr string     `json:"stderr,omitempty"`
+	Error  string     `json:"error"`
+	Script *ScriptRef `json:"script,omitempty"`
+
+	// OutputTruncated is set when Output holds only the end of the output. OutputSize is the size of the
+	// full output, and OutputRef the bucket/key it was stored under in the artifact store.
+	OutputTruncated bool   `json:"output_truncated,omitempty"`
+	OutputSize      int    `json:"output_size,omitempty"`
+	OutputRef       string `json:"output_ref,omitempty"`
+
+	StartedAt  *metav1.Time     `json:"started_at,omitempty"`
+	FinishedAt *metav1.Time     `json:"finished_at,omitempty"`
+	Duration   *metav1.Duration `json:"duration,omitempty"`
+	// ExitCode is the exit code of the task command or script
+	ExitCode *int32 `json:"exit_code,omitempty"`
+	PodName  string `json:"pod_name,omitempty"`
+	NodeName string `json:"node_name,omitempty"`
+	// Attempt is the number of times the task pod was started for this run
+	Attempt int32 `json:"attempt,omitempty"`
+}
+
+// ScriptRef records the ConfigMap or Secret a script was read from when the task ran
+type ScriptRef struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Key             string `json:"key"`
+	ResourceVersion string `json:"resource_version"`
 }
 
 // Workflow is the Schema for the workflows API
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=workflows,singular=workflow,shortName=wf,scope=Namespaced
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Run",type=string,JSONPath=`.status.conditions[?(@.type=="LastRunSucceeded")].reason`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Next Schedule",type=date,JSONPath=`.status.nextScheduleTime`,priority=1
+// +kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessfulTime`,priority=1
+// +kubebuilder:printcolumn:name="Succeeded",type=integer,JSONPath=`.status.succeededRuns`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedRuns`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type Workflow struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
@@ -57,12 +225,58 @@ type Workflow struct {
 }
 
 // WorkflowList contains a list of Workflow
+// +kubebuilder:object:root=true
 type WorkflowList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata"`
 	Items           []Workflow `json:"items"`
 }
 
+// WorkflowRunSpec identifies the workflow a run belongs to and holds the definition the run executes.
+// It is set when the run is created and does not change afterwards.
+type WorkflowRunSpec struct {
+	Workflow string `json:"workflow"`
+
+	// WorkflowGeneration is the generation of the workflow the snapshot was taken from
+	WorkflowGeneration int64 `json:"workflowGeneration,omitempty"`
+	// SpecHash is the hash of WorkflowSpec, as returned by WorkflowSpec.Hash
+	SpecHash string `json:"specHash,omitempty"`
+	// WorkflowSpec is a copy of the spec of the workflow when the run started. Runs recorded by older
+	// versions have no snapshot.
+	WorkflowSpec *WorkflowSpec `json:"workflowSpec,omitempty"`
+}
+
+// RunName returns the name of the WorkflowRun with the given ID. The name identifies a run everywhere:
+// in the jobs and pods of its tasks, in the artifact store and when the executor reports on a task.
+func RunName(workflow string, id int) string {
+	return workflow + "-run-" + strconv.Itoa(id)
+}
+
+// WorkflowRun is a single execution of a Workflow
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=workflowruns,singular=workflowrun,shortName=wfr,scope=Namespaced
+// +kubebuilder:printcolumn:name="Workflow",type=string,JSONPath=`.spec.workflow`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Generation",type=integer,JSONPath=`.spec.workflowGeneration`,priority=1
+// +kubebuilder:printcolumn:name="Started",type=date,JSONPath=`.status.started_at`
+// +kubebuilder:printcolumn:name="Ended",type=date,JSONPath=`.status.ended_at`
+type WorkflowRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   WorkflowRunSpec `json:"spec"`
+	Status Workflowruns    `json:"status"`
+}
+
+// WorkflowRunList contains a list of WorkflowRun
+// +kubebuilder:object:root=true
+type WorkflowRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []WorkflowRun `json:"items"`
+}
+
 //Artifact store credentials
 type MinioCreds struct {
 	AccessKey string `json:"accesskey"`
@@ -70,5 +284,5 @@ type MinioCreds struct {
 }
 
 func init() {
-	SchemeBuilder.Register(&Workflow{}, &WorkflowList{})
+	SchemeBuilder.Register(&Workflow{}, &WorkflowList{}, &WorkflowRun{}, &WorkflowRunList{})
 }
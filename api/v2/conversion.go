@@ -0,0 +1,150 @@
+package v2
+
+import (
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+)
+
+// ConvertTo converts wf to the v1 workflow dst. Workflows are stored as v1.
+func (wf *Workflow) ConvertTo(dst *wfv1.Workflow) error {
+	src := wf.DeepCopy()
+
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = wfv1.GroupVersion.String()
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = wfv1.WorkflowSpec{
+		Schedule:                   src.Spec.Schedule,
+		StoreArtifacts:             src.Spec.StoreArtifacts,
+		Env:                        src.Spec.Env,
+		EnvFrom:                    src.Spec.EnvFrom,
+		SecretMounts:               secretMountsToV1(src.Spec.SecretMounts),
+		PodDefaults:                (*wfv1.PodOptions)(src.Spec.PodDefaults),
+		ServiceAccountName:         src.Spec.ServiceAccountName,
+		OutputLimit:                src.Spec.OutputLimit,
+		SuccessfulRunsHistoryLimit: src.Spec.SuccessfulRunsHistoryLimit,
+		FailedRunsHistoryLimit:     src.Spec.FailedRunsHistoryLimit,
+		RunTTL:                     src.Spec.RunTTL,
+	}
+	for _, task := range src.Spec.Tasks {
+		t := wfv1.Workflowtask{
+			Name:         task.Name,
+			Env:          task.Env,
+			EnvFrom:      task.EnvFrom,
+			SecretMounts: secretMountsToV1(task.SecretMounts),
+			PodOptions:   wfv1.PodOptions(task.PodOptions),
+		}
+		if task.Command.Inline != nil {
+			t.Command.Inline.Command = task.Command.Inline.Command
+			t.Command.Inline.Args = task.Command.Inline.Args
+		}
+		t.Command.Script = task.Command.Script
+		t.Command.ScriptFrom = (*wfv1.ScriptSource)(task.Command.ScriptFrom)
+		dst.Spec.Tasks = append(dst.Spec.Tasks, t)
+	}
+
+	dst.Status = wfv1.WorkflowStatus{
+		TotalRuns:          src.Status.TotalRuns,
+		SucceededRuns:      src.Status.SucceededRuns,
+		FailedRuns:         src.Status.FailedRuns,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastScheduleTime:   src.Status.LastScheduleTime,
+		NextScheduleTime:   src.Status.NextScheduleTime,
+		LastSuccessfulTime: src.Status.LastSuccessfulTime,
+		Conditions:         src.Status.Conditions,
+	}
+	if run := src.Status.LastRun; run != nil {
+		dst.Status.LastRun = &wfv1.RunSummary{
+			Name:      run.Name,
+			ID:        run.ID,
+			Phase:     run.Phase,
+			StartedAt: run.StartedAt,
+			EndedAt:   run.EndedAt,
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1 workflow src to wf. Runs recorded on the status by older versions are left
+// out: v2 has no field for them, and the controller moves them to WorkflowRuns.
+func (wf *Workflow) ConvertFrom(src *wfv1.Workflow) error {
+	src = src.DeepCopy()
+
+	wf.TypeMeta = src.TypeMeta
+	wf.APIVersion = GroupVersion.String()
+	wf.ObjectMeta = src.ObjectMeta
+
+	wf.Spec = WorkflowSpec{
+		Schedule:                   src.Spec.Schedule,
+		StoreArtifacts:             src.Spec.StoreArtifacts,
+		Env:                        src.Spec.Env,
+		EnvFrom:                    src.Spec.EnvFrom,
+		SecretMounts:               secretMountsFromV1(src.Spec.SecretMounts),
+		PodDefaults:                (*PodOptions)(src.Spec.PodDefaults),
+		ServiceAccountName:         src.Spec.ServiceAccountName,
+		OutputLimit:                src.Spec.OutputLimit,
+		SuccessfulRunsHistoryLimit: src.Spec.SuccessfulRunsHistoryLimit,
+		FailedRunsHistoryLimit:     src.Spec.FailedRunsHistoryLimit,
+		RunTTL:                     src.Spec.RunTTL,
+	}
+	for _, task := range src.Spec.Tasks {
+		t := Task{
+			Name: task.Name,
+			Command: Command{
+				Script:     task.Command.Script,
+				ScriptFrom: (*ScriptSource)(task.Command.ScriptFrom),
+			},
+			Env:          task.Env,
+			EnvFrom:      task.EnvFrom,
+			SecretMounts: secretMountsFromV1(task.SecretMounts),
+			PodOptions:   PodOptions(task.PodOptions),
+		}
+		if inline := task.Command.Inline; inline.Command != "" || len(inline.Args) > 0 {
+			t.Command.Inline = &InlineCommand{Command: inline.Command, Args: inline.Args}
+		}
+		wf.Spec.Tasks = append(wf.Spec.Tasks, t)
+	}
+
+	wf.Status = WorkflowStatus{
+		TotalRuns:          src.Status.TotalRuns,
+		SucceededRuns:      src.Status.SucceededRuns,
+		FailedRuns:         src.Status.FailedRuns,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastScheduleTime:   src.Status.LastScheduleTime,
+		NextScheduleTime:   src.Status.NextScheduleTime,
+		LastSuccessfulTime: src.Status.LastSuccessfulTime,
+		Conditions:         src.Status.Conditions,
+	}
+	if run := src.Status.LastRun; run != nil {
+		wf.Status.LastRun = &RunSummary{
+			Name:      run.Name,
+			ID:        run.ID,
+			Phase:     run.Phase,
+			StartedAt: run.StartedAt,
+			EndedAt:   run.EndedAt,
+		}
+	}
+	return nil
+}
+
+// secretMountsToV1 and secretMountsFromV1 convert secret mounts, whose fields are the same in both versions
+func secretMountsToV1(mounts []SecretMount) []wfv1.SecretMount {
+	if mounts == nil {
+		return nil
+	}
+	out := make([]wfv1.SecretMount, len(mounts))
+	for i, m := range mounts {
+		out[i] = wfv1.SecretMount(m)
+	}
+	return out
+}
+
+func secretMountsFromV1(mounts []wfv1.SecretMount) []SecretMount {
+	if mounts == nil {
+		return nil
+	}
+	out := make([]SecretMount, len(mounts))
+	for i, m := range mounts {
+		out[i] = SecretMount(m)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package v2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func v1Workflow() *wfv1.Workflow {
+	now := metav1.NewTime(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	limit := int32(100)
+
+	wf := &wfv1.Workflow{}
+	wf.APIVersion = wfv1.GroupVersion.String()
+	wf.Kind = "WorkFlow"
+	wf.Name = "wf1"
+	wf.Namespace = "default"
+	wf.Annotations = map[string]string{"team": "a"}
+	wf.Spec.Schedule = "*/5 * * * *"
+	wf.Spec.StoreArtifacts = true
+	wf.Spec.OutputLimit = &limit
+	wf.Spec.SecretMounts = []wfv1.SecretMount{{SecretName: "creds", MountPath: "/creds"}}
+	wf.Spec.PodDefaults = &wfv1.PodOptions{NodeSelector: map[string]string{"disk": "ssd"}}
+	wf.Spec.Tasks = make([]wfv1.Workflowtask, 2)
+	wf.Spec.Tasks[0].Name = "build"
+	wf.Spec.Tasks[0].Command.Inline.Command = "make"
+	wf.Spec.Tasks[0].Command.Inline.Args = []string{"all"}
+	wf.Spec.Tasks[0].PriorityClassName = "high"
+	wf.Spec.Tasks[1].Name = "deploy"
+	wf.Spec.Tasks[1].Command.ScriptFrom = &wfv1.ScriptSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "scripts"}, Key: "deploy.sh"},
+	}
+	wf.Spec.Tasks[1].Env = []corev1.EnvVar{{Name: "STAGE", Value: "prod"}}
+
+	wf.Status.TotalRuns = 2
+	wf.Status.SucceededRuns = 1
+	wf.Status.LastScheduleTime = &now
+	wf.Status.LastRun = &wfv1.RunSummary{Name: "wf1-run-2", ID: 2, Phase: wfv1.RunFailed, StartedAt: &now, EndedAt: &now}
+	wf.Status.LegacyRuns = []wfv1.Workflowruns{{ID: 1, Phase: wfv1.RunSucceeded, StartedAt: &now, Tasks: []wfv1.TaskStatus{{Name: "build", Status: wfv1.TaskSucceeded, Output: "ok"}}}}
+	return wf
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	src := v1Workflow()
+
+	var wf Workflow
+	err := wf.ConvertFrom(src)
+	if err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if wf.APIVersion != GroupVersion.String() {
+		t.Errorf("got apiVersion %q, want %q", wf.APIVersion, GroupVersion.String())
+	}
+	if inline := wf.Spec.Tasks[0].Command.Inline; inline == nil || inline.Command != "make" || !reflect.DeepEqual(inline.Args, []string{"all"}) {
+		t.Errorf("got inline command %+v, want make all", inline)
+	}
+	if wf.Spec.Tasks[1].Command.Inline != nil {
+		t.Errorf("got inline command %+v for a task with a script, want none", wf.Spec.Tasks[1].Command.Inline)
+	}
+	if wf.Status.LastRun.Phase != wfv1.RunFailed || !wf.Status.LastRun.StartedAt.Equal(src.Status.LastRun.StartedAt) {
+		t.Errorf("got last run %+v, want the last run of the v1 workflow", wf.Status.LastRun)
+	}
+	if !reflect.DeepEqual(wf.Annotations, src.Annotations) {
+		t.Errorf("got annotations %v, want those of the v1 workflow", wf.Annotations)
+	}
+
+	var dst wfv1.Workflow
+	err = wf.ConvertTo(&dst)
+	if err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+	// runs recorded by older versions are moved to WorkflowRuns by the controller and are not kept in v2
+	want := src.DeepCopy()
+	want.Status.LegacyRuns = nil
+	if !reflect.DeepEqual(&dst, want) {
+		t.Errorf("got %+v after converting to v2 and back, want %+v", dst, *want)
+	}
+}
+
+func TestConvertFromLongLegacyHistory(t *testing.T) {
+	src := v1Workflow()
+	output := strings.Repeat("x", 2048)
+	for id := 2; id <= 1000; id++ {
+		src.Status.LegacyRuns = append(src.Status.LegacyRuns, wfv1.Workflowruns{ID: id, Phase: wfv1.RunSucceeded, Tasks: []wfv1.TaskStatus{{Name: "build", Status: wfv1.TaskSucceeded, Output: output}}})
+	}
+
+	var wf Workflow
+	err := wf.ConvertFrom(src)
+	if err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	size := 0
+	for k, v := range wf.Annotations {
+		size += len(k) + len(v)
+	}
+	if size > 256*1024 || len(wf.Annotations) != len(src.Annotations) {
+		t.Errorf("got %d annotations of %d bytes, want only those of the v1 workflow", len(wf.Annotations), size)
+	}
+}
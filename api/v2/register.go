@@ -0,0 +1,22 @@
+// Package v2 holds version v2 of the workflows API. Workflows are stored as v1, the API server converts
+// them through the conversion webhook of the controller.
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:Optional
+// +groupName=trinity.cloudlego.com
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "trinity.cloudlego.com", Version: "v2"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
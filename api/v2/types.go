@@ -0,0 +1,153 @@
+package v2
+
+import (
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkflowSpec defines the desired state of Workflow
+type WorkflowSpec struct {
+	// Schedule is the cron schedule the workflow runs on
+	// +kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
+	// +kubebuilder:default="*/5 * * * *"
+	Schedule string `json:"schedule,omitempty"`
+	// StoreArtifacts deploys an artifact store for the runs of the workflow
+	StoreArtifacts bool   `json:"storeArtifacts,omitempty"`
+	Tasks          []Task `json:"tasks"`
+
+	// Env, EnvFrom and SecretMounts are applied to every task. A task can override them.
+	Env          []corev1.EnvVar        `json:"env,omitempty"`
+	EnvFrom      []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	SecretMounts []SecretMount          `json:"secretMounts,omitempty"`
+
+	// PodDefaults applies to the runner, task and artifact store pods of the workflow
+	PodDefaults *PodOptions `json:"podDefaults,omitempty"`
+
+	// ServiceAccountName is the service account the runner and task pods run under.
+	// Defaults to trinity-runner, which the controller maintains in the workflow's namespace.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// OutputLimit is the number of bytes of a task's output kept in the run status
+	// +kubebuilder:validation:Minimum=0
+	OutputLimit *int32 `json:"outputLimit,omitempty"`
+
+	// SuccessfulRunsHistoryLimit and FailedRunsHistoryLimit are the number of finished runs to keep.
+	// RunTTL is how long a finished run is kept. Runs are kept forever when these are not set.
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulRunsHistoryLimit *int32 `json:"successfulRunsHistoryLimit,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	FailedRunsHistoryLimit *int32           `json:"failedRunsHistoryLimit,omitempty"`
+	RunTTL                 *metav1.Duration `json:"runTTL,omitempty"`
+}
+
+// Task is a step of a workflow, run in a pod of its own
+type Task struct {
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]*$`
+	Name    string  `json:"name"`
+	Command Command `json:"command"`
+
+	Env          []corev1.EnvVar        `json:"env,omitempty"`
+	EnvFrom      []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	SecretMounts []SecretMount          `json:"secretMounts,omitempty"`
+
+	// PodOptions set on a task override the workflow PodDefaults for the task pod
+	PodOptions `json:",inline"`
+}
+
+// Command is what a task runs: exactly one of an inline command, a script or a script from a ConfigMap or Secret
+type Command struct {
+	Inline     *InlineCommand `json:"inline,omitempty"`
+	Script     string         `json:"script,omitempty"`
+	ScriptFrom *ScriptSource  `json:"scriptFrom,omitempty"`
+}
+
+// InlineCommand is an executable run with arguments
+type InlineCommand struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// PodOptions holds the resources and scheduling constraints of a pod started for a workflow
+type PodOptions struct {
+	Resources                *corev1.ResourceRequirements `json:"resources,omitempty"`
+	NodeSelector             map[string]string            `json:"nodeSelector,omitempty"`
+	Tolerations              []corev1.Toleration          `json:"tolerations,omitempty"`
+	Affinity                 *corev1.Affinity             `json:"affinity,omitempty"`
+	PriorityClassName        string                       `json:"priorityClassName,omitempty"`
+	SecurityContext          *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+	ContainerSecurityContext *corev1.SecurityContext      `json:"containerSecurityContext,omitempty"`
+}
+
+// SecretMount mounts a Secret as a read-only volume in the task container
+type SecretMount struct {
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+	// +kubebuilder:validation:Required
+	MountPath string             `json:"mountPath"`
+	Items     []corev1.KeyToPath `json:"items,omitempty"`
+}
+
+// ScriptSource selects a script stored under a key of a ConfigMap or a Secret
+type ScriptSource struct {
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+// WorkflowStatus defines the observed state of Workflow.
+// The history of runs is kept in WorkflowRun objects owned by the workflow.
+type WorkflowStatus struct {
+	LastRun       *RunSummary `json:"lastRun,omitempty"`
+	TotalRuns     int         `json:"totalRuns,omitempty"`
+	SucceededRuns int         `json:"succeededRuns,omitempty"`
+	FailedRuns    int         `json:"failedRuns,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the controller last acted on
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	LastScheduleTime   *metav1.Time       `json:"lastScheduleTime,omitempty"`
+	NextScheduleTime   *metav1.Time       `json:"nextScheduleTime,omitempty"`
+	LastSuccessfulTime *metav1.Time       `json:"lastSuccessfulTime,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// RunSummary describes the last run of a workflow
+type RunSummary struct {
+	Name      string        `json:"name"`
+	ID        int           `json:"id"`
+	Phase     wfv1.RunPhase `json:"phase"`
+	StartedAt *metav1.Time  `json:"startedAt,omitempty"`
+	EndedAt   *metav1.Time  `json:"endedAt,omitempty"`
+}
+
+// Workflow is the Schema for the workflows API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=workflows,singular=workflow,shortName=wf,scope=Namespaced
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Run",type=string,JSONPath=`.status.conditions[?(@.type=="LastRunSucceeded")].reason`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Next Schedule",type=date,JSONPath=`.status.nextScheduleTime`,priority=1
+// +kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessfulTime`,priority=1
+// +kubebuilder:printcolumn:name="Succeeded",type=integer,JSONPath=`.status.succeededRuns`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedRuns`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type Workflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkflowSpec   `json:"spec"`
+	Status WorkflowStatus `json:"status,omitempty"`
+}
+
+// WorkflowList contains a list of Workflow
+// +kubebuilder:object:root=true
+type WorkflowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Workflow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Workflow{}, &WorkflowList{})
+}
@@ -2,10 +2,19 @@ package ctrl
 
 import (
 	"github.com/arunprasadmudaliar/trinity/pkg/controller"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/arunprasadmudaliar/trinity/pkg/webhook"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var kubeconfig string
+var serveWebhook bool
+var webhookAddr string
+var webhookService string
+var webhookNamespace string
+var webhookPort int32
+var certDir string
 
 //Cmd for version number
 var Cmd = &cobra.Command{
@@ -15,10 +24,53 @@ var Cmd = &cobra.Command{
 	//Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		config, _ := cmd.Flags().GetString("kubeconfig")
-		controller.Start(config)
+		cfg, err := utils.Config(config)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to build client configuration")
+		}
+
+		ctx, cancel := utils.SignalContext()
+		defer cancel()
+
+		//webhookErr receives why the webhook server stopped
+		webhookErr := make(chan error, 1)
+		if serveWebhook {
+			opts := webhook.Options{
+				Addr: webhookAddr,
+				Service: utils.WebhookService{
+					Name:      webhookService,
+					Namespace: webhookNamespace,
+					Port:      webhookPort,
+				},
+				CertDir: certDir,
+			}
+			//the controller is stopped along with the webhook server
+			go func() {
+				webhookErr <- webhook.Start(ctx, cfg, opts, logrus.StandardLogger())
+				cancel()
+			}()
+		}
+
+		err = controller.Start(ctx, cfg, logrus.StandardLogger())
+		if err != nil {
+			logrus.WithError(err).Fatal("Controller stopped")
+		}
+		//a failed webhook server fails the controller, so its pod is restarted
+		if serveWebhook {
+			err = <-webhookErr
+			if err != nil {
+				logrus.WithError(err).Fatal("Webhook server stopped")
+			}
+		}
 	},
 }
 
 func init() {
 	Cmd.Flags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "path to kubeconfig file")
+	Cmd.Flags().BoolVar(&serveWebhook, "webhook", false, "also serve the webhooks that validate workflows and apply their defaults")
+	Cmd.Flags().StringVar(&webhookAddr, "webhook-addr", ":9443", "address the webhook server listens on")
+	Cmd.Flags().StringVar(&webhookService, "webhook-service", "trinity-webhook", "service the API server reaches the webhook server through")
+	Cmd.Flags().StringVar(&webhookNamespace, "webhook-namespace", "default", "namespace of the webhook service")
+	Cmd.Flags().Int32Var(&webhookPort, "webhook-port", 443, "port of the webhook service")
+	Cmd.Flags().StringVar(&certDir, "cert-dir", "", "directory with tls.crt and tls.key of the webhook server. Certificates are generated and kept in a secret when it is empty")
 }
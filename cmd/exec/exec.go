@@ -2,14 +2,16 @@ package exec
 
 import (
 	"github.com/arunprasadmudaliar/trinity/pkg/executor"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var workflow string
-var runid int
+var runname string
 var taskid int
 var namespace string
-var kubeconfig string
+var last bool
 
 //Cmd for exec
 var Cmd = &cobra.Command{
@@ -20,23 +22,34 @@ var Cmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		wf, _ := cmd.Flags().GetString("workflow")
 		ns, _ := cmd.Flags().GetString("namespace")
-		config, _ := cmd.Flags().GetString("kubeconfig")
-		runid, _ := cmd.Flags().GetInt("runid")
+		runname, _ := cmd.Flags().GetString("run")
 		taskid, _ := cmd.Flags().GetInt("taskid")
+		last, _ := cmd.Flags().GetBool("last")
 
-		executor.Execute(config, wf, ns, runid, taskid)
-
+		ctx, cancel := utils.SignalContext()
+		defer cancel()
+		_, err := executor.Execute(ctx, executor.Options{
+			Workflow:  wf,
+			Namespace: ns,
+			Run:       runname,
+			TaskID:    taskid,
+			Last:      last,
+			Log:       logrus.StandardLogger(),
+		})
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to report result of task %d", taskid)
+		}
 	},
 }
 
 func init() {
-	Cmd.Flags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "path to kubeconfig file")
 	Cmd.Flags().StringVarP(&workflow, "workflow", "w", "", "name of the workflow")
 	Cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "namespace of the workflow")
-	Cmd.Flags().IntVarP(&runid, "runid", "r", 0, "run id")
+	Cmd.Flags().StringVarP(&runname, "run", "r", "", "name of the WorkflowRun the task belongs to")
 	Cmd.Flags().IntVarP(&taskid, "taskid", "t", 0, "task id")
+	Cmd.Flags().BoolVarP(&last, "last", "l", false, "whether this is the last task of the workflow")
 	Cmd.MarkFlagRequired("workflow")
 	Cmd.MarkFlagRequired("namespace")
-	Cmd.MarkFlagRequired("runid")
+	Cmd.MarkFlagRequired("run")
 	Cmd.MarkFlagRequired("taskid")
 }
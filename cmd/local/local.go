@@ -0,0 +1,70 @@
+package local
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arunprasadmudaliar/trinity/pkg/runner"
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var file string
+var dir string
+var keep bool
+
+//Cmd for local
+var Cmd = &cobra.Command{
+	Use:   "local",
+	Short: "Works with workflows on this machine, without a cluster",
+	Long:  ``,
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Runs the tasks of a workflow manifest as local processes",
+	Long: `Runs the tasks of a workflow manifest one after another as processes on this machine and prints the
+status of the run. The output of a task is passed to the next task in WF_INPUT and /artifacts/incoming
+and /artifacts/outgoing are mapped to temporary directories. Stdout and stderr of the tasks are written
+to stderr. The command fails when the run does not succeed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		dir, _ := cmd.Flags().GetString("dir")
+		keep, _ := cmd.Flags().GetBool("keep")
+
+		wf, err := sdk.ReadWorkflow(file)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to read workflow")
+		}
+
+		backend := runner.NewLocalBackend(logrus.StandardLogger())
+		backend.Dir = dir
+		backend.Keep = keep
+		backend.Output = os.Stderr
+
+		ctx, cancel := utils.SignalContext()
+		defer cancel()
+		run, err := runner.RunLocal(ctx, wf, backend, logrus.StandardLogger())
+		if run != nil {
+			summary, _ := yaml.Marshal(run.Status)
+			fmt.Print(string(summary))
+		}
+		if err != nil {
+			logrus.WithError(err).Fatalf("Local run of workflow %s failed", wf.Name)
+		}
+		if !run.Status.Succeeded() {
+			logrus.Fatalf("Local run of workflow %s ended in phase %s", wf.Name, run.Status.Phase)
+		}
+	},
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&file, "file", "f", "", "path to the workflow manifest")
+	runCmd.Flags().StringVarP(&dir, "dir", "d", "", "directory the tasks run in, the default directory for temporary files when empty")
+	runCmd.Flags().BoolVar(&keep, "keep", false, "keep the directories of the tasks once the run is completed")
+	runCmd.MarkFlagRequired("file")
+	Cmd.AddCommand(runCmd)
+}
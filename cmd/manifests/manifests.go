@@ -0,0 +1,32 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/arunprasadmudaliar/trinity/pkg/manifests"
+	"github.com/spf13/cobra"
+)
+
+//Cmd for manifests
+var Cmd = &cobra.Command{
+	Use:   "manifests",
+	Short: "Prints the manifests trinity is installed with",
+	Long:  ``,
+}
+
+var crdCmd = &cobra.Command{
+	Use:   "crd",
+	Short: "Prints the CustomResourceDefinitions of workflows and workflow runs",
+	Long: `Prints the CustomResourceDefinitions of workflows and workflow runs this version of trinity serves,
+as generated from its API types, so they can be applied before the controller is upgraded:
+
+  trinity manifests crd | kubectl apply -f -`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(manifests.CRD())
+	},
+}
+
+func init() {
+	Cmd.AddCommand(crdCmd)
+}
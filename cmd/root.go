@@ -5,7 +5,10 @@ import (
 
 	"github.com/arunprasadmudaliar/trinity/cmd/ctrl"
 	"github.com/arunprasadmudaliar/trinity/cmd/exec"
+	"github.com/arunprasadmudaliar/trinity/cmd/local"
+	"github.com/arunprasadmudaliar/trinity/cmd/manifests"
 	"github.com/arunprasadmudaliar/trinity/cmd/run"
+	"github.com/arunprasadmudaliar/trinity/cmd/validate"
 	"github.com/arunprasadmudaliar/trinity/cmd/version"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -31,4 +34,7 @@ func init() {
 	rootCmd.AddCommand(ctrl.Cmd)
 	rootCmd.AddCommand(run.Cmd)
 	rootCmd.AddCommand(exec.Cmd)
+	rootCmd.AddCommand(local.Cmd)
+	rootCmd.AddCommand(validate.Cmd)
+	rootCmd.AddCommand(manifests.Cmd)
 }
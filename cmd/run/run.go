@@ -2,6 +2,8 @@ package run
 
 import (
 	"github.com/arunprasadmudaliar/trinity/pkg/runner"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +21,17 @@ var Cmd = &cobra.Command{
 		config, _ := cmd.Flags().GetString("kubeconfig")
 		name, _ := cmd.Flags().GetString("name")
 		ns, _ := cmd.Flags().GetString("namespace")
-		runner.Run(config, name, ns)
+		cfg, err := utils.Config(config)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to build workflow client configuration")
+		}
+
+		ctx, cancel := utils.SignalContext()
+		defer cancel()
+		err = runner.Run(ctx, cfg, name, ns, logrus.StandardLogger())
+		if err != nil {
+			logrus.WithError(err).Fatalf("Run of workflow %s under namespace %s failed", name, ns)
+		}
 	},
 }
 
@@ -0,0 +1,92 @@
+package validate
+
+import (
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+)
+
+//sarifLog is the subset of SARIF 2.1.0 code scanning tools read
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+//rules describes the rules of sdk.Lint
+var rules = []sarifRule{
+	{sdk.RuleSyntax, sarifMessage{"Manifests must be valid YAML"}},
+	{sdk.RuleSchema, sarifMessage{"Workflows must match the schema of the Workflow type"}},
+	{sdk.RuleRequired, sarifMessage{"Required fields must be set"}},
+	{sdk.RuleInvalid, sarifMessage{"Fields must hold valid values"}},
+	{sdk.RuleDuplicate, sarifMessage{"Task names must be unique"}},
+	{sdk.RuleReference, sarifMessage{"Scripts must be taken from existing ConfigMaps and Secrets"}},
+}
+
+//sarif converts diagnostics to a SARIF log, e.g. for code scanning in CI
+func sarif(diags []sdk.Diagnostic) sarifLog {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "trinity"
+	run.Tool.Driver.InformationURI = "https://github.com/arunprasadmudaliar/trinity"
+	run.Tool.Driver.Rules = rules
+
+	for _, d := range diags {
+		result := sarifResult{RuleID: d.Rule, Level: d.Severity, Message: sarifMessage{d.Message}}
+		if d.Field != "" {
+			result.Message.Text = d.Field + ": " + d.Message
+		}
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = d.File
+		//lines are unknown for files that could not be read as a whole
+		if d.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+		}
+		result.Locations = []sarifLocation{location}
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
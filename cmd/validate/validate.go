@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var files []string
+var output string
+
+//Cmd for validate
+var Cmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks workflow manifests without a cluster",
+	Long: `Checks the workflows in manifests against the schema of the Workflow type, the cron syntax of their
+schedule, the names and commands of their tasks and the ConfigMaps and Secrets their scripts are taken
+from. Diagnostics are printed as text, JSON or SARIF with the file and line they were found at. The
+command fails when an error is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		files, _ := cmd.Flags().GetStringSlice("file")
+		output, _ := cmd.Flags().GetString("output")
+
+		diags, err := sdk.LintFiles(append(files, args...)...)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to read manifests")
+		}
+
+		switch output {
+		case "text":
+			for _, d := range diags {
+				fmt.Println(d)
+			}
+		case "json":
+			err = writeJSON(diags)
+		case "sarif":
+			err = writeJSON(sarif(diags))
+		default:
+			logrus.Fatalf("Unknown output format %s, want one of text, json and sarif", output)
+		}
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to print diagnostics")
+		}
+
+		for _, d := range diags {
+			if d.Severity == sdk.SeverityError {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+//writeJSON prints v as indented JSON to stdout
+func writeJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func init() {
+	Cmd.Flags().StringSliceVarP(&files, "file", "f", nil, "manifests to check, also taken from the arguments")
+	Cmd.Flags().StringVarP(&output, "output", "o", "text", "format of the diagnostics, one of text, json and sarif")
+}
@@ -0,0 +1,157 @@
+//Command generate generates the code and manifests derived from the API types in api/: the deepcopy functions
+//in zz_generated.deepcopy.go of every version, the CRDs in deployments/crd.yaml and their copy compiled into
+//the trinity binary in pkg/manifests/zz_generated.crd.go. It runs the object and crd generators of
+//controller-gen, so the markers of the types are read as by controller-gen, and adjusts the CRDs for what
+//the markers cannot express. It lives in a module of its own to keep controller-tools out of the
+//dependencies of trinity, and is run with go generate ./pkg/manifests.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/deepcopy"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/yaml"
+)
+
+const header = "# Code generated by hack/generate from the types in api/. DO NOT EDIT.\n"
+
+//versionAnnotation records the version of controller-gen that generated a CRD
+const versionAnnotation = "controller-gen.kubebuilder.io/version"
+
+//crds lists the generated CRDs in the order they are written to deployments/crd.yaml
+var crds = []string{"workflows", "workflowruns"}
+
+func main() {
+	root := "."
+	if len(os.Args) > 1 {
+		root = os.Args[1]
+	}
+	err := os.Chdir(root)
+	if err != nil {
+		fail(err)
+	}
+
+	dir, err := ioutil.TempDir("", "trinity-crds")
+	if err != nil {
+		fail(err)
+	}
+	defer os.RemoveAll(dir)
+
+	//descriptions are left out, as kubectl explain is not worth the size of the descriptions of core types
+	maxDescLen := 0
+	var objectGen genall.Generator = deepcopy.Generator{}
+	var crdGen genall.Generator = crd.Generator{MaxDescLen: &maxDescLen}
+	rt, err := genall.Generators{&objectGen, &crdGen}.ForRoots("./api/...")
+	if err != nil {
+		fail(err)
+	}
+	//code is written next to the types, the CRDs to dir
+	rt.OutputRules = genall.OutputRules{Default: genall.OutputArtifacts{Config: genall.OutputToDirectory(dir)}}
+	if rt.Run() {
+		fail(fmt.Errorf("failed to generate code and CRDs"))
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	for i, name := range crds {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "trinity.cloudlego.com_"+name+".yaml"))
+		if err != nil {
+			fail(err)
+		}
+		var obj map[string]interface{}
+		err = yaml.Unmarshal(data, &obj)
+		if err != nil {
+			fail(err)
+		}
+		pinVersion(obj)
+		if name == "workflows" {
+			adjustWorkflowCRD(obj)
+		}
+		data, err = yaml.Marshal(obj)
+		if err != nil {
+			fail(err)
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+
+	err = ioutil.WriteFile(filepath.Join("deployments", "crd.yaml"), out.Bytes(), 0644)
+	if err != nil {
+		fail(err)
+	}
+	err = ioutil.WriteFile(filepath.Join("pkg", "manifests", "zz_generated.crd.go"), goSource(out.String()), 0644)
+	if err != nil {
+		fail(err)
+	}
+}
+
+//adjustWorkflowCRD keeps the kind workflows were registered with, which differs from the name of the Go type.
+//The conversion of the CRD is left out, it is managed by the controller along with the CA it trusts, and
+//applying the CRD again would otherwise drop the CA.
+func adjustWorkflowCRD(obj map[string]interface{}) {
+	spec := obj["spec"].(map[string]interface{})
+	names := spec["names"].(map[string]interface{})
+	names["kind"] = "WorkFlow"
+	names["listKind"] = "WorkFlowList"
+}
+
+//pinVersion records the version of controller-tools in go.mod in the version annotation of a CRD. The generators
+//would record the version of the binary they run in, which is (devel) or a pseudo-version depending on how
+//it was built, and change the CRDs with every build.
+func pinVersion(obj map[string]interface{}) {
+	metadata := obj["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	annotations[versionAnnotation] = controllerToolsVersion()
+}
+
+//controllerToolsVersion returns the version of controller-tools the generator was built with
+func controllerToolsVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		fail(fmt.Errorf("failed to read build information of the generator"))
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "sigs.k8s.io/controller-tools" {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	fail(fmt.Errorf("controller-tools is not a dependency of the generator"))
+	return ""
+}
+
+//goSource returns the Go source of pkg/manifests holding the CRDs
+func goSource(crds string) []byte {
+	var src bytes.Buffer
+	src.WriteString("// Code generated by hack/generate from the types in api/. DO NOT EDIT.\n\n")
+	src.WriteString("package manifests\n\n")
+	src.WriteString("const crd = ")
+	if strings.Contains(crds, "`") {
+		fmt.Fprintf(&src, "%q", crds)
+	} else {
+		src.WriteString("`" + crds + "`")
+	}
+	src.WriteString("\n")
+	return src.Bytes()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
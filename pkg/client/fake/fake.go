@@ -0,0 +1,251 @@
+//Package fake provides an in-memory workflow client for tests, in the style of the fake kubernetes clientset
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/testing"
+)
+
+var (
+	workflowsResource    = wfv1.GroupVersion.WithResource("workflows")
+	workflowsKind        = wfv1.GroupVersion.WithKind(wfv1.Kind)
+	workflowRunsResource = wfv1.GroupVersion.WithResource("workflowruns")
+	workflowRunsKind     = wfv1.GroupVersion.WithKind("WorkflowRun")
+)
+
+//Clientset implements wfv1.WorkFlowV1Interface on top of an object tracker. Every call is recorded as an
+//action, and reactors can be prepended to change the outcome of calls.
+type Clientset struct {
+	testing.Fake
+	tracker testing.ObjectTracker
+}
+
+var _ wfv1.WorkFlowV1Interface = &Clientset{}
+
+//NewSimpleClientset returns a Clientset holding the given Workflows and WorkflowRuns
+func NewSimpleClientset(objects ...runtime.Object) *Clientset {
+	o := testing.NewObjectTracker(wfv1.Scheme, wfv1.Codecs.UniversalDecoder())
+	for _, obj := range objects {
+		//the tracker would add a Workflow once for each of its kinds, so the resource is given explicitly
+		var err error
+		switch obj := obj.(type) {
+		case *wfv1.Workflow:
+			err = o.Create(workflowsResource, obj, obj.Namespace)
+		case *wfv1.WorkflowRun:
+			err = o.Create(workflowRunsResource, obj, obj.Namespace)
+		default:
+			err = fmt.Errorf("unsupported object %T", obj)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	cs := &Clientset{tracker: o}
+	reaction := testing.ObjectReaction(o)
+	cs.AddReactor("patch", "*", func(action testing.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(testing.PatchActionImpl)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		//the tracker cannot apply. For the fields of a single manager a merge patch has the same effect.
+		patch.PatchType = types.MergePatchType
+		return reaction(patch)
+	})
+	cs.AddReactor("*", "*", reaction)
+	cs.AddWatchReactor("*", func(action testing.Action) (bool, watch.Interface, error) {
+		w, err := o.Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		return true, w, nil
+	})
+	return cs
+}
+
+//Tracker returns the tracker holding the objects of the Clientset
+func (c *Clientset) Tracker() testing.ObjectTracker {
+	return c.tracker
+}
+
+//RESTClient returns nil, the fake does not talk to an API server
+func (c *Clientset) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
+
+func (c *Clientset) WorkFlows(namespace string) wfv1.WorkFlowInterface {
+	return &workflows{fake: c, ns: namespace}
+}
+
+func (c *Clientset) WorkFlowRuns(namespace string) wfv1.WorkFlowRunInterface {
+	return &workflowRuns{fake: c, ns: namespace}
+}
+
+type workflows struct {
+	fake *Clientset
+	ns   string
+}
+
+func (c *workflows) List(ctx context.Context, opts metav1.ListOptions) (*wfv1.WorkflowList, error) {
+	obj, err := c.fake.Invokes(testing.NewListAction(workflowsResource, workflowsKind, c.ns, opts), &wfv1.WorkflowList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	selector := selectorOf(opts)
+	list := &wfv1.WorkflowList{ListMeta: obj.(*wfv1.WorkflowList).ListMeta}
+	for _, item := range obj.(*wfv1.WorkflowList).Items {
+		if selector.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *workflows) Get(ctx context.Context, name string, opts metav1.GetOptions) (*wfv1.Workflow, error) {
+	obj, err := c.fake.Invokes(testing.NewGetAction(workflowsResource, c.ns, name), &wfv1.Workflow{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.Workflow), err
+}
+
+func (c *workflows) Create(ctx context.Context, workflow *wfv1.Workflow, opts metav1.CreateOptions) (*wfv1.Workflow, error) {
+	obj, err := c.fake.Invokes(testing.NewCreateAction(workflowsResource, c.ns, workflow), &wfv1.Workflow{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.Workflow), err
+}
+
+func (c *workflows) Update(ctx context.Context, workflow *wfv1.Workflow, opts metav1.UpdateOptions) (*wfv1.Workflow, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateAction(workflowsResource, c.ns, workflow), &wfv1.Workflow{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.Workflow), err
+}
+
+func (c *workflows) UpdateStatus(ctx context.Context, workflow *wfv1.Workflow, opts metav1.UpdateOptions) (*wfv1.Workflow, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateSubresourceAction(workflowsResource, "status", c.ns, workflow), &wfv1.Workflow{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.Workflow), err
+}
+
+func (c *workflows) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	_, err := c.fake.Invokes(testing.NewDeleteAction(workflowsResource, c.ns, name), &wfv1.Workflow{})
+	return err
+}
+
+func (c *workflows) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewWatchAction(workflowsResource, c.ns, opts))
+}
+
+func (c *workflows) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*wfv1.Workflow, error) {
+	obj, err := c.fake.Invokes(testing.NewPatchSubresourceAction(workflowsResource, c.ns, name, pt, data, subresources...), &wfv1.Workflow{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.Workflow), err
+}
+
+func (c *workflows) ApplyStatus(ctx context.Context, workflow *wfv1.Workflow, fieldManager string) (*wfv1.Workflow, error) {
+	data, err := json.Marshal(map[string]interface{}{"status": workflow.Status})
+	if err != nil {
+		return nil, err
+	}
+	force := true
+	return c.Patch(ctx, workflow.Name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force}, "status")
+}
+
+type workflowRuns struct {
+	fake *Clientset
+	ns   string
+}
+
+func (c *workflowRuns) List(ctx context.Context, opts metav1.ListOptions) (*wfv1.WorkflowRunList, error) {
+	obj, err := c.fake.Invokes(testing.NewListAction(workflowRunsResource, workflowRunsKind, c.ns, opts), &wfv1.WorkflowRunList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	selector := selectorOf(opts)
+	list := &wfv1.WorkflowRunList{ListMeta: obj.(*wfv1.WorkflowRunList).ListMeta}
+	for _, item := range obj.(*wfv1.WorkflowRunList).Items {
+		if selector.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+func (c *workflowRuns) Get(ctx context.Context, name string, opts metav1.GetOptions) (*wfv1.WorkflowRun, error) {
+	obj, err := c.fake.Invokes(testing.NewGetAction(workflowRunsResource, c.ns, name), &wfv1.WorkflowRun{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.WorkflowRun), err
+}
+
+func (c *workflowRuns) Create(ctx context.Context, run *wfv1.WorkflowRun, opts metav1.CreateOptions) (*wfv1.WorkflowRun, error) {
+	obj, err := c.fake.Invokes(testing.NewCreateAction(workflowRunsResource, c.ns, run), &wfv1.WorkflowRun{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.WorkflowRun), err
+}
+
+func (c *workflowRuns) Update(ctx context.Context, run *wfv1.WorkflowRun, opts metav1.UpdateOptions) (*wfv1.WorkflowRun, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateAction(workflowRunsResource, c.ns, run), &wfv1.WorkflowRun{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.WorkflowRun), err
+}
+
+func (c *workflowRuns) UpdateStatus(ctx context.Context, run *wfv1.WorkflowRun, opts metav1.UpdateOptions) (*wfv1.WorkflowRun, error) {
+	obj, err := c.fake.Invokes(testing.NewUpdateSubresourceAction(workflowRunsResource, "status", c.ns, run), &wfv1.WorkflowRun{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.WorkflowRun), err
+}
+
+func (c *workflowRuns) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	_, err := c.fake.Invokes(testing.NewDeleteAction(workflowRunsResource, c.ns, name), &wfv1.WorkflowRun{})
+	return err
+}
+
+func (c *workflowRuns) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.fake.InvokesWatch(testing.NewWatchAction(workflowRunsResource, c.ns, opts))
+}
+
+func (c *workflowRuns) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*wfv1.WorkflowRun, error) {
+	obj, err := c.fake.Invokes(testing.NewPatchSubresourceAction(workflowRunsResource, c.ns, name, pt, data, subresources...), &wfv1.WorkflowRun{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*wfv1.WorkflowRun), err
+}
+
+//selectorOf returns the label selector of list options, which selects everything when none is set
+func selectorOf(opts metav1.ListOptions) labels.Selector {
+	selector, _, _ := testing.ExtractFromListOptions(opts)
+	if selector == nil {
+		return labels.Everything()
+	}
+	return selector
+}
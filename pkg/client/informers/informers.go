@@ -0,0 +1,240 @@
+//Package informers watches workflows and runs through the typed workflow client and caches them. The package
+//is written by hand against the workflow client in api/v1, and follows the shape of the informers
+//informer-gen generates for client-go clientsets.
+package informers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/listers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+//TweakListOptionsFunc changes the options the informers list and watch with, e.g. to select by label
+type TweakListOptionsFunc func(*metav1.ListOptions)
+
+//NewInformerFunc creates an informer whose list and watch requests are made with ctx
+type NewInformerFunc func(ctx context.Context, client wfv1.WorkFlowV1Interface, resync time.Duration) cache.SharedIndexInformer
+
+//NewWorkflowInformer returns an informer for the workflows of a namespace, or of all namespaces when
+//namespace is empty. Its list and watch requests are made with ctx.
+func NewWorkflowInformer(ctx context.Context, client wfv1.WorkFlowV1Interface, namespace string, resync time.Duration, indexers cache.Indexers, tweak TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				if tweak != nil {
+					tweak(&opts)
+				}
+				return client.WorkFlows(namespace).List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				if tweak != nil {
+					tweak(&opts)
+				}
+				return client.WorkFlows(namespace).Watch(ctx, opts)
+			},
+		},
+		&wfv1.Workflow{},
+		resync,
+		indexers,
+	)
+}
+
+//NewWorkflowRunInformer returns an informer for the runs of a namespace, or of all namespaces when
+//namespace is empty. Its list and watch requests are made with ctx.
+func NewWorkflowRunInformer(ctx context.Context, client wfv1.WorkFlowV1Interface, namespace string, resync time.Duration, indexers cache.Indexers, tweak TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				if tweak != nil {
+					tweak(&opts)
+				}
+				return client.WorkFlowRuns(namespace).List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				if tweak != nil {
+					tweak(&opts)
+				}
+				return client.WorkFlowRuns(namespace).Watch(ctx, opts)
+			},
+		},
+		&wfv1.WorkflowRun{},
+		resync,
+		indexers,
+	)
+}
+
+//WorkflowInformer gives access to a shared informer for workflows and a lister reading from its cache
+type WorkflowInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() listers.WorkflowLister
+}
+
+//WorkflowRunInformer gives access to a shared informer for runs and a lister reading from its cache
+type WorkflowRunInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() listers.WorkflowRunLister
+}
+
+//GenericInformer gives access to a shared informer and a lister of untyped objects, for code that works
+//with any resource
+type GenericInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() cache.GenericLister
+}
+
+//SharedInformerFactory hands out informers that are shared by everyone asking for the same type
+type SharedInformerFactory interface {
+	Workflows() WorkflowInformer
+	WorkflowRuns() WorkflowRunInformer
+	//ForResource returns the informer for a resource of the workflow API
+	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
+	//InformerFor returns the shared informer for the type of obj, created with newFunc when there is none yet
+	InformerFor(obj runtime.Object, newFunc NewInformerFunc) cache.SharedIndexInformer
+	//Start runs all informers requested so far until stopCh is closed
+	Start(stopCh <-chan struct{})
+	//WaitForCacheSync waits for the caches of all started informers to be filled
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+}
+
+//NewSharedInformerFactory returns a factory for informers watching namespace, or all namespaces when
+//namespace is empty. The list and watch requests of the informers are made with ctx, so they end when
+//ctx is cancelled.
+func NewSharedInformerFactory(ctx context.Context, client wfv1.WorkFlowV1Interface, namespace string, resync time.Duration, tweak TweakListOptionsFunc) SharedInformerFactory {
+	return &factory{
+		ctx:       ctx,
+		client:    client,
+		namespace: namespace,
+		resync:    resync,
+		tweak:     tweak,
+		informers: map[reflect.Type]cache.SharedIndexInformer{},
+		started:   map[reflect.Type]bool{},
+	}
+}
+
+type factory struct {
+	ctx       context.Context
+	client    wfv1.WorkFlowV1Interface
+	namespace string
+	resync    time.Duration
+	tweak     TweakListOptionsFunc
+
+	lock      sync.Mutex
+	informers map[reflect.Type]cache.SharedIndexInformer
+	started   map[reflect.Type]bool
+}
+
+func (f *factory) InformerFor(obj runtime.Object, newFunc NewInformerFunc) cache.SharedIndexInformer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	t := reflect.TypeOf(obj)
+	informer, ok := f.informers[t]
+	if !ok {
+		informer = newFunc(f.ctx, f.client, f.resync)
+		f.informers[t] = informer
+	}
+	return informer
+}
+
+func (f *factory) Workflows() WorkflowInformer {
+	informer := f.InformerFor(&wfv1.Workflow{}, func(ctx context.Context, client wfv1.WorkFlowV1Interface, resync time.Duration) cache.SharedIndexInformer {
+		return NewWorkflowInformer(ctx, client, f.namespace, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweak)
+	})
+	return &workflowInformer{informer: informer, lister: listers.NewWorkflowLister(informer.GetIndexer())}
+}
+
+func (f *factory) WorkflowRuns() WorkflowRunInformer {
+	informer := f.InformerFor(&wfv1.WorkflowRun{}, func(ctx context.Context, client wfv1.WorkFlowV1Interface, resync time.Duration) cache.SharedIndexInformer {
+		return NewWorkflowRunInformer(ctx, client, f.namespace, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweak)
+	})
+	return &workflowRunInformer{informer: informer, lister: listers.NewWorkflowRunLister(informer.GetIndexer())}
+}
+
+func (f *factory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
+	var informer cache.SharedIndexInformer
+	switch resource {
+	case wfv1.GroupVersion.WithResource("workflows"):
+		informer = f.Workflows().Informer()
+	case wfv1.GroupVersion.WithResource("workflowruns"):
+		informer = f.WorkflowRuns().Informer()
+	default:
+		return nil, fmt.Errorf("no informer found for %v", resource)
+	}
+	return &genericInformer{informer: informer, lister: cache.NewGenericLister(informer.GetIndexer(), resource.GroupResource())}, nil
+}
+
+func (f *factory) Start(stopCh <-chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for t, informer := range f.informers {
+		if !f.started[t] {
+			go informer.Run(stopCh)
+			f.started[t] = true
+		}
+	}
+}
+
+func (f *factory) WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool {
+	f.lock.Lock()
+	informers := map[reflect.Type]cache.SharedIndexInformer{}
+	for t, informer := range f.informers {
+		if f.started[t] {
+			informers[t] = informer
+		}
+	}
+	f.lock.Unlock()
+
+	synced := map[reflect.Type]bool{}
+	for t, informer := range informers {
+		synced[t] = cache.WaitForCacheSync(stopCh, informer.HasSynced)
+	}
+	return synced
+}
+
+type workflowInformer struct {
+	informer cache.SharedIndexInformer
+	lister   listers.WorkflowLister
+}
+
+func (i *workflowInformer) Informer() cache.SharedIndexInformer {
+	return i.informer
+}
+
+func (i *workflowInformer) Lister() listers.WorkflowLister {
+	return i.lister
+}
+
+type workflowRunInformer struct {
+	informer cache.SharedIndexInformer
+	lister   listers.WorkflowRunLister
+}
+
+func (i *workflowRunInformer) Informer() cache.SharedIndexInformer {
+	return i.informer
+}
+
+func (i *workflowRunInformer) Lister() listers.WorkflowRunLister {
+	return i.lister
+}
+
+type genericInformer struct {
+	informer cache.SharedIndexInformer
+	lister   cache.GenericLister
+}
+
+func (i *genericInformer) Informer() cache.SharedIndexInformer {
+	return i.informer
+}
+
+func (i *genericInformer) Lister() cache.GenericLister {
+	return i.lister
+}
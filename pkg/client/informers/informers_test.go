@@ -0,0 +1,49 @@
+package informers
+
+import (
+	"context"
+	"testing"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSharedInformerFactory(t *testing.T) {
+	wf := &wfv1.Workflow{ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: "default"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := NewSharedInformerFactory(ctx, fake.NewSimpleClientset(wf), metav1.NamespaceAll, 0, nil)
+	workflows := f.Workflows()
+	if f.Workflows().Informer() != workflows.Informer() {
+		t.Error("got a new informer for workflows, want the shared one")
+	}
+	generic, err := f.ForResource(wfv1.GroupVersion.WithResource("workflows"))
+	if err != nil {
+		t.Fatalf("ForResource failed: %v", err)
+	}
+	if generic.Informer() != workflows.Informer() {
+		t.Error("got a new informer for the workflows resource, want the shared one")
+	}
+	_, err = f.ForResource(wfv1.GroupVersion.WithResource("pods"))
+	if err == nil {
+		t.Error("ForResource succeeded for a resource outside of the workflow API")
+	}
+
+	f.Start(ctx.Done())
+	for typ, synced := range f.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			t.Fatalf("cache of %v did not sync", typ)
+		}
+	}
+
+	got, err := workflows.Lister().Workflows("default").Get("wf1")
+	if err != nil || got.Name != "wf1" {
+		t.Errorf("got workflow %v and error %v from the lister, want wf1", got, err)
+	}
+	obj, err := generic.Lister().ByNamespace("default").Get("wf1")
+	if err != nil || obj.(*wfv1.Workflow).Name != "wf1" {
+		t.Errorf("got %v and error %v from the generic lister, want wf1", obj, err)
+	}
+}
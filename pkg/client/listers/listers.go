@@ -0,0 +1,124 @@
+//Package listers lists and gets workflows and runs from the cache of an informer. Like package informers it
+//is written by hand, following the shape of the listers lister-gen generates.
+package listers
+
+import (
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+//WorkflowLister lists workflows in all namespaces from an indexer
+type WorkflowLister interface {
+	List(selector labels.Selector) ([]*wfv1.Workflow, error)
+	Workflows(namespace string) WorkflowNamespaceLister
+}
+
+//WorkflowNamespaceLister lists and gets workflows of a single namespace from an indexer
+type WorkflowNamespaceLister interface {
+	List(selector labels.Selector) ([]*wfv1.Workflow, error)
+	Get(name string) (*wfv1.Workflow, error)
+}
+
+//NewWorkflowLister returns a WorkflowLister backed by indexer
+func NewWorkflowLister(indexer cache.Indexer) WorkflowLister {
+	return &workflowLister{indexer: indexer}
+}
+
+type workflowLister struct {
+	indexer cache.Indexer
+}
+
+func (l *workflowLister) List(selector labels.Selector) ([]*wfv1.Workflow, error) {
+	var ret []*wfv1.Workflow
+	err := cache.ListAll(l.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*wfv1.Workflow))
+	})
+	return ret, err
+}
+
+func (l *workflowLister) Workflows(namespace string) WorkflowNamespaceLister {
+	return &workflowNamespaceLister{indexer: l.indexer, namespace: namespace}
+}
+
+type workflowNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+func (l *workflowNamespaceLister) List(selector labels.Selector) ([]*wfv1.Workflow, error) {
+	var ret []*wfv1.Workflow
+	err := cache.ListAllByNamespace(l.indexer, l.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*wfv1.Workflow))
+	})
+	return ret, err
+}
+
+func (l *workflowNamespaceLister) Get(name string) (*wfv1.Workflow, error) {
+	obj, exists, err := l.indexer.GetByKey(l.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(wfv1.GroupVersion.WithResource("workflows").GroupResource(), name)
+	}
+	return obj.(*wfv1.Workflow), nil
+}
+
+//WorkflowRunLister lists runs in all namespaces from an indexer
+type WorkflowRunLister interface {
+	List(selector labels.Selector) ([]*wfv1.WorkflowRun, error)
+	WorkflowRuns(namespace string) WorkflowRunNamespaceLister
+}
+
+//WorkflowRunNamespaceLister lists and gets runs of a single namespace from an indexer
+type WorkflowRunNamespaceLister interface {
+	List(selector labels.Selector) ([]*wfv1.WorkflowRun, error)
+	Get(name string) (*wfv1.WorkflowRun, error)
+}
+
+//NewWorkflowRunLister returns a WorkflowRunLister backed by indexer
+func NewWorkflowRunLister(indexer cache.Indexer) WorkflowRunLister {
+	return &workflowRunLister{indexer: indexer}
+}
+
+type workflowRunLister struct {
+	indexer cache.Indexer
+}
+
+func (l *workflowRunLister) List(selector labels.Selector) ([]*wfv1.WorkflowRun, error) {
+	var ret []*wfv1.WorkflowRun
+	err := cache.ListAll(l.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*wfv1.WorkflowRun))
+	})
+	return ret, err
+}
+
+func (l *workflowRunLister) WorkflowRuns(namespace string) WorkflowRunNamespaceLister {
+	return &workflowRunNamespaceLister{indexer: l.indexer, namespace: namespace}
+}
+
+type workflowRunNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+func (l *workflowRunNamespaceLister) List(selector labels.Selector) ([]*wfv1.WorkflowRun, error) {
+	var ret []*wfv1.WorkflowRun
+	err := cache.ListAllByNamespace(l.indexer, l.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*wfv1.WorkflowRun))
+	})
+	return ret, err
+}
+
+func (l *workflowRunNamespaceLister) Get(name string) (*wfv1.WorkflowRun, error) {
+	obj, exists, err := l.indexer.GetByKey(l.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(wfv1.GroupVersion.WithResource("workflowruns").GroupResource(), name)
+	}
+	return obj.(*wfv1.WorkflowRun), nil
+}
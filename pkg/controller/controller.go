@@ -1,30 +1,28 @@
 package controller
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
 
 	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/informers"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/listers"
 	"github.com/arunprasadmudaliar/trinity/pkg/utils"
 	"github.com/sirupsen/logrus"
 
 	v1 "k8s.io/api/core/v1"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 
 	//"k8s.io/client-go/informers"
 
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -40,8 +38,14 @@ type workflow struct {
 
 type controller struct {
 	client   kubernetes.Interface
+	wfclient wfv1.WorkFlowV1Interface
 	informer cache.SharedIndexInformer
-	queue    workqueue.RateLimitingInterface
+	lister   listers.WorkflowLister
+	//runInformer caches the runs of all workflows, which are read through runLister
+	runInformer cache.SharedIndexInformer
+	runLister   listers.WorkflowRunLister
+	queue       workqueue.RateLimitingInterface
+	log         logrus.FieldLogger
 }
 
 type schedule struct {
@@ -50,43 +54,29 @@ type schedule struct {
 	}
 }
 
-//Start fuction with start the controller
-func Start(config string) {
-	// This client will be used when a clientset is required
-	kc, err := utils.Client(config)
-	if err != nil {
-		logrus.Fatal(err)
-	}
+//Start runs the controller for the cluster described by config until ctx is cancelled
+func Start(ctx context.Context, config *rest.Config, log logrus.FieldLogger) error {
+	log = utils.Logger(log)
 
-	//Below client is created directly from config without a clientset
-	cfg, err := clientcmd.BuildConfigFromFlags("", config)
+	kc, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		logrus.WithError(err).Fatal("Failed to build client configuration")
+		return fmt.Errorf("failed to create kubernetes client: %v", err)
 	}
 
-	dc, err := dynamic.NewForConfig(cfg)
+	wc, err := wfv1.NewForConfig(config)
 	if err != nil {
-		logrus.WithError(err).Fatal("Failed to create dynamic client for given configuration")
+		return fmt.Errorf("failed to create workflow client: %v", err)
 	}
 
-	f := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dc, 0, v1.NamespaceAll, nil)
-	gvr, _ := schema.ParseResourceArg("workflows.v1.trinity.cloudlego.com") // group.version.resource or GroupVersionResource
-
-	i := f.ForResource(*gvr) // we create a new informer here
-
-	//factory := informers.NewSharedInformerFactory(kc, 0) // For clientset
-	//informer := factory.Core().V1().Pods().Informer()
-	//c := newController(kc, i.Informer())
-
-	c := newController(kc, i.Informer())
-	stopCh := make(chan struct{})
-	defer close(stopCh)
-
-	c.Run(stopCh)
+	f := informers.NewSharedInformerFactory(ctx, wc, v1.NamespaceAll, 0, nil)
+	i := f.Workflows() // we create a new informer here
 
+	c := newController(kc, wc, i, f.WorkflowRuns(), log)
+	return c.Run(ctx)
 }
 
-func newController(kc *kubernetes.Clientset, informer cache.SharedIndexInformer) *controller {
+func newController(kc kubernetes.Interface, wc wfv1.WorkFlowV1Interface, wi informers.WorkflowInformer, ri informers.WorkflowRunInformer, log logrus.FieldLogger) *controller {
+	informer := wi.Informer()
 	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
 	var wf workflow
 	var err error
@@ -102,14 +92,8 @@ func newController(kc *kubernetes.Clientset, informer cache.SharedIndexInformer)
 		UpdateFunc: func(old, new interface{}) {
 			//Push to queue only if there is a change in spec and not status.
 
-			oldWf, err := unstructuredToWorkflow(old.(*unstructured.Unstructured))
-			if err != nil {
-				logrus.WithError(err).Errorf("Failed to unmarshal workflow object %s", oldWf)
-			}
-			newWf, err := unstructuredToWorkflow(new.(*unstructured.Unstructured))
-			if err != nil {
-				logrus.WithError(err).Errorf("Failed to unmarshal workflow object %s", newWf)
-			}
+			oldWf := old.(*wfv1.Workflow)
+			newWf := new.(*wfv1.Workflow)
 
 			if !reflect.DeepEqual(oldWf.Spec, newWf.Spec) {
 				wf.key, err = cache.MetaNamespaceKeyFunc(old)
@@ -130,58 +114,71 @@ func newController(kc *kubernetes.Clientset, informer cache.SharedIndexInformer)
 		},
 	})
 	return &controller{
-		client:   kc,
-		informer: informer,
-		queue:    q,
+		client:      kc,
+		wfclient:    wc,
+		informer:    informer,
+		lister:      wi.Lister(),
+		runInformer: ri.Informer(),
+		runLister:   ri.Lister(),
+		queue:       q,
+		log:         log,
 	}
 }
 
-func (c *controller) Run(stopper <-chan struct{}) {
+//Run processes workflow events until ctx is cancelled
+func (c *controller) Run(ctx context.Context) error {
 
 	defer utilruntime.HandleCrash() //this will handle panic and won't crash the process
 	defer c.queue.ShutDown()        //shutdown all workqueue and terminate all workers
 
-	logrus.Info("Starting workflow controller...")
+	c.log.Info("Starting workflow controller...")
 
-	go c.informer.Run(stopper)
+	go c.informer.Run(ctx.Done())
+	go c.runInformer.Run(ctx.Done())
 
-	logrus.Info("Synchronizing events...")
+	c.log.Info("Synchronizing events...")
 
 	//synchronize the cache before starting to process events
-	if !cache.WaitForCacheSync(stopper, c.informer.HasSynced) {
-		utilruntime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-		logrus.Info("synchronization failed...")
-		return
+	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced, c.runInformer.HasSynced) {
+		c.log.Info("synchronization failed...")
+		return fmt.Errorf("timed out waiting for caches to sync")
 	}
 
-	logrus.Info("synchronization complete")
+	c.log.Info("synchronization complete")
+
+	go wait.UntilWithContext(ctx, c.pruneRuns, pruneInterval)
+	go wait.UntilWithContext(ctx, c.refreshStatus, statusInterval)
+	go wait.UntilWithContext(ctx, c.runWorker, time.Second)
 
-	wait.Until(c.runWorker, time.Second, stopper)
+	//shutting down the queue on return stops the worker waiting for the next item
+	<-ctx.Done()
+	c.log.Info("Stopping workflow controller...")
+	return nil
 }
 
-func (c *controller) runWorker() {
-	for c.processNextItem() {
+func (c *controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
 		// continue looping
 	}
 }
 
-func (c *controller) processNextItem() bool {
+func (c *controller) processNextItem(ctx context.Context) bool {
 	wf, quit := c.queue.Get()
 
 	if quit {
 		return false
 	}
 	defer c.queue.Done(wf.(workflow))
-	err := c.processItem(wf.(workflow))
+	err := c.processItem(ctx, wf.(workflow))
 	if err == nil {
 		// No error, reset the ratelimit counters
 		c.queue.Forget(wf)
 	} /* else if c.queue.NumRequeues(wf) < maxRetries {
-		logrus.Errorf("Error processing %s (will retry): %v", wf.(workflow).key, err)
+		c.log.Errorf("Error processing %s (will retry): %v", wf.(workflow).key, err)
 		c.queue.AddRateLimited(wf)
 	} else {
 		// err != nil and too many retries
-		logrus.Errorf("Error processing %s (giving up): %v", wf.(workflow).key, err)
+		c.log.Errorf("Error processing %s (giving up): %v", wf.(workflow).key, err)
 		c.queue.Forget(wf)
 		utilruntime.HandleError(err)
 	} */
@@ -189,82 +186,86 @@ func (c *controller) processNextItem() bool {
 	return true
 }
 
-func (c *controller) processItem(wf workflow) error {
+func (c *controller) processItem(ctx context.Context, wf workflow) error {
 	obj, _, err := c.informer.GetIndexer().GetByKey(wf.key)
 	if err != nil {
-		logrus.WithError(err).Errorf("Failed to fetch workflow %s from store", wf.key)
+		c.log.WithError(err).Errorf("Failed to fetch workflow %s from store", wf.key)
 		return err
 	}
 
 	var schedule wfv1.Workflow
 	if wf.action != "delete" {
-		j, err := obj.(*unstructured.Unstructured).MarshalJSON()
-		if err != nil {
-			logrus.WithError(err).Errorf("Failed to process %s event for workflow %s", wf.action, wf.key)
-			return err
-		}
-		err = json.Unmarshal(j, &schedule)
-		if err != nil {
-			logrus.WithError(err).Errorf("Failed to process %s event for workflow %s", wf.action, wf.key)
-			return err
+		if obj == nil {
+			c.log.Infof("Workflow %s was removed before its %s event was processed", wf.key, wf.action)
+			return nil
 		}
+		//objects from the cache are shared and must not be changed
+		schedule = *obj.(*wfv1.Workflow).DeepCopy()
 	}
 
 	ns := strings.Split(wf.key, "/")[0]
 	name := strings.Split(wf.key, "/")[1]
 
+	if wf.action != "delete" {
+		err = c.reconcile(ctx, wf, name, ns, &schedule)
+		statusErr := c.syncStatus(ctx, &schedule, err)
+		if statusErr != nil {
+			c.log.WithError(statusErr).Errorf("Failed to update status of workflow %s", wf.key)
+		}
+		return err
+	}
+
+	deleted, err := utils.DeleteCron(ctx, c.client, name, ns)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to delete Cron wf-cron-%s for %s", name, wf.key)
+		return err
+	}
+	if deleted {
+		c.log.Infof("Removed Cron wf-cron-%s for %s", name, wf.key)
+		return nil
+	}
+
+	c.log.Infof("Did not find a Cron wf-cron-%s for %s to delete", name, wf.key)
+	return nil
+
+}
+
+//reconcile sets up everything a created or updated workflow needs to run
+func (c *controller) reconcile(ctx context.Context, wf workflow, name string, ns string, schedule *wfv1.Workflow) error {
+	err := c.migrateRuns(ctx, schedule)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to move run history of workflow %s", wf.key)
+		return err
+	}
+
+	err = utils.EnsureRunnerRBAC(ctx, c.client, ns)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to set up service account %s in namespace %s", utils.RunnerServiceAccount, ns)
+		return err
+	}
+
 	switch wf.action {
 	case "create":
-		created, err := utils.CreateCron(c.client.(*kubernetes.Clientset), name, ns, schedule.Spec.Schedule)
+		created, err := utils.CreateCron(ctx, c.client, name, ns, &schedule.Spec)
 		if err != nil {
-			logrus.WithError(err).Errorf("Failed to create Cron for %s", wf.key)
+			c.log.WithError(err).Errorf("Failed to create Cron for %s", wf.key)
 			return err
 		}
 
 		if created {
-			logrus.Infof("Created Cron wf-cron-%s for %s", name, wf.key)
+			c.log.Infof("Created Cron wf-cron-%s for %s", name, wf.key)
 			return nil
 		}
-		logrus.Infof("Found a Cron wf-cron-%s for %s", name, wf.key)
+		c.log.Infof("Found a Cron wf-cron-%s for %s", name, wf.key)
 		return nil
 
 	case "update":
-		err = utils.UpdateCron(c.client.(*kubernetes.Clientset), name, ns, schedule.Spec.Schedule)
+		err = utils.UpdateCron(ctx, c.client, name, ns, &schedule.Spec)
 		if err != nil {
-			logrus.WithError(err).Errorf("Failed to Update Cron wf-cron-%s for %s", name, wf.key)
+			c.log.WithError(err).Errorf("Failed to Update Cron wf-cron-%s for %s", name, wf.key)
 			return err
 		}
-		logrus.Infof("Updated Cron wf-cron-%s for %s", name, wf.key)
-
-	case "delete":
-		deleted, err := utils.DeleteCron(c.client.(*kubernetes.Clientset), name, ns)
-		if err != nil {
-			logrus.WithError(err).Errorf("Failed to delete Cron wf-cron-%s for %s", name, wf.key)
-			return err
-		}
-		if deleted {
-			logrus.Infof("Removed Cron wf-cron-%s for %s", name, wf.key)
-			return nil
-		}
-
-		logrus.Infof("Did not find a Cron wf-cron-%s for %s to delete", name, wf.key)
-		return nil
+		c.log.Infof("Updated Cron wf-cron-%s for %s", name, wf.key)
 	}
 	return nil
-
-}
-
-func unstructuredToWorkflow(obj *unstructured.Unstructured) (wfv1.Workflow, error) {
-	var wf wfv1.Workflow
-	j, err := obj.MarshalJSON()
-	if err != nil {
-		//	logrus.WithError(err).Errorf("Failed to unmarshal workflow object %s", obj)
-		return wf, err
-	}
-	err = json.Unmarshal(j, &wf)
-	if err != nil {
-		//	logrus.WithError(err).Errorf("Failed to unmarshal workflow object %s", obj)
-		return wf, err
-	}
-	return wf, nil
 }
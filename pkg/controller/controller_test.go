@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/fake"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/informers"
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const namespace = "default"
+
+func testWorkflow(schedule string) *wfv1.Workflow {
+	return &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace, UID: "wf1-uid", Generation: 3},
+		Spec:       sdk.NewWorkflow("wf1", namespace).Schedule(schedule).Task(sdk.Inline("hello", "echo", "hello")).Spec(),
+	}
+}
+
+//newTestController returns a controller working on fake clients holding wf. wf is in the informer cache
+//as well, as if the informer had seen it.
+func newTestController(t *testing.T, wf *wfv1.Workflow) (*controller, *kubefake.Clientset, *fake.Clientset) {
+	kc := kubefake.NewSimpleClientset()
+	wc := fake.NewSimpleClientset(wf)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	f := informers.NewSharedInformerFactory(context.Background(), wc, metav1.NamespaceAll, 0, nil)
+	wi := f.Workflows()
+	c := newController(kc, wc, wi, f.WorkflowRuns(), log)
+	err := wi.Informer().GetIndexer().Add(wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, kc, wc
+}
+
+func getWorkflow(t *testing.T, wc *fake.Clientset) *wfv1.Workflow {
+	wf, err := wc.WorkFlows(namespace).Get(context.Background(), "wf1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get workflow: %v", err)
+	}
+	return wf
+}
+
+func TestReconcileCreate(t *testing.T) {
+	c, kc, wc := newTestController(t, testWorkflow("*/10 * * * *"))
+	ctx := context.Background()
+
+	err := c.processItem(ctx, workflow{key: namespace + "/wf1", action: "create"})
+	if err != nil {
+		t.Fatalf("processItem failed: %v", err)
+	}
+
+	cron, err := kc.BatchV1beta1().CronJobs(namespace).Get(ctx, "wf-cron-wf1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cronjob was not created: %v", err)
+	}
+	if cron.Spec.Schedule != "*/10 * * * *" {
+		t.Errorf("got schedule %q, want the schedule of the workflow", cron.Spec.Schedule)
+	}
+
+	_, err = kc.CoreV1().ServiceAccounts(namespace).Get(ctx, utils.RunnerServiceAccount, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("runner service account was not created: %v", err)
+	}
+	roles, _ := kc.RbacV1().Roles(namespace).List(ctx, metav1.ListOptions{})
+	bindings, _ := kc.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+	if len(roles.Items) != 1 || len(bindings.Items) != 1 {
+		t.Errorf("got %d roles and %d role bindings, want one of each for the runner", len(roles.Items), len(bindings.Items))
+	}
+	for _, rule := range roles.Items[0].Rules {
+		for _, resource := range rule.Resources {
+			if resource == "workflows" && (len(rule.Verbs) != 1 || rule.Verbs[0] != "get") {
+				t.Errorf("runner may %v workflows, want it to only get them", rule.Verbs)
+			}
+		}
+	}
+
+	status := getWorkflow(t, wc).Status
+	if status.ObservedGeneration != 3 {
+		t.Errorf("got observed generation %d, want 3", status.ObservedGeneration)
+	}
+	for _, condition := range []string{wfv1.ConditionReady, wfv1.ConditionScheduled} {
+		if !meta.IsStatusConditionTrue(status.Conditions, condition) {
+			t.Errorf("condition %s is not true: %+v", condition, status.Conditions)
+		}
+	}
+	if status.NextScheduleTime == nil {
+		t.Errorf("next schedule time was not set")
+	}
+}
+
+func TestReconcileUpdate(t *testing.T) {
+	c, kc, _ := newTestController(t, testWorkflow("*/10 * * * *"))
+	ctx := context.Background()
+
+	err := c.processItem(ctx, workflow{key: namespace + "/wf1", action: "create"})
+	if err != nil {
+		t.Fatalf("processItem failed: %v", err)
+	}
+
+	updated := testWorkflow("0 * * * *")
+	updated.Generation = 4
+	err = c.informer.GetIndexer().Update(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.processItem(ctx, workflow{key: namespace + "/wf1", action: "update"})
+	if err != nil {
+		t.Fatalf("processItem failed: %v", err)
+	}
+
+	cron, err := kc.BatchV1beta1().CronJobs(namespace).Get(ctx, "wf-cron-wf1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cronjob is missing: %v", err)
+	}
+	if cron.Spec.Schedule != "0 * * * *" {
+		t.Errorf("got schedule %q, want the updated schedule", cron.Spec.Schedule)
+	}
+}
+
+func TestReconcileDelete(t *testing.T) {
+	c, kc, _ := newTestController(t, testWorkflow("*/10 * * * *"))
+	ctx := context.Background()
+
+	err := c.processItem(ctx, workflow{key: namespace + "/wf1", action: "create"})
+	if err != nil {
+		t.Fatalf("processItem failed: %v", err)
+	}
+
+	err = c.informer.GetIndexer().Delete(testWorkflow(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.processItem(ctx, workflow{key: namespace + "/wf1", action: "delete"})
+	if err != nil {
+		t.Fatalf("processItem failed: %v", err)
+	}
+
+	_, err = kc.BatchV1beta1().CronJobs(namespace).Get(ctx, "wf-cron-wf1", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("cronjob was not removed: %v", err)
+	}
+}
+
+func TestReconcileFailureSetsReadyFalse(t *testing.T) {
+	c, kc, wc := newTestController(t, testWorkflow("*/10 * * * *"))
+	kc.PrependReactor("create", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("quota exceeded")
+	})
+
+	err := c.processItem(context.Background(), workflow{key: namespace + "/wf1", action: "create"})
+	if err == nil {
+		t.Fatal("processItem succeeded, want the error creating the cronjob")
+	}
+
+	status := getWorkflow(t, wc).Status
+	ready := meta.FindStatusCondition(status.Conditions, wfv1.ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "ReconcileFailed" || ready.Message != "quota exceeded" {
+		t.Errorf("got ready condition %+v, want it false with the reconcile error", ready)
+	}
+	if status.ObservedGeneration != 0 {
+		t.Errorf("got observed generation %d, want the failed generation not to be observed", status.ObservedGeneration)
+	}
+}
+
+func TestSyncStatusOfPrunedLastRun(t *testing.T) {
+	ended := metav1.Now()
+	tests := []struct {
+		name   string
+		phase  wfv1.RunPhase
+		want   metav1.ConditionStatus
+		reason string
+	}{
+		{"the phase of the summary decides", wfv1.RunFailed, metav1.ConditionFalse, "RunFailed"},
+		{"an older summary keeps the previous outcome", "", metav1.ConditionTrue, "RunSucceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf := testWorkflow("*/10 * * * *")
+			//wf1-run-2 was pruned, the condition was set when wf1-run-1 finished
+			wf.Status.LastRun = &wfv1.RunSummary{Name: "wf1-run-2", ID: 2, Phase: tt.phase, EndedAt: &ended}
+			meta.SetStatusCondition(&wf.Status.Conditions, metav1.Condition{
+				Type:    wfv1.ConditionLastRunSucceeded,
+				Status:  metav1.ConditionTrue,
+				Reason:  "RunSucceeded",
+				Message: "run wf1-run-1 succeeded",
+			})
+			c, _, wc := newTestController(t, wf)
+
+			err := c.syncStatus(context.Background(), wf.DeepCopy(), nil)
+			if err != nil {
+				t.Fatalf("syncStatus failed: %v", err)
+			}
+
+			condition := meta.FindStatusCondition(getWorkflow(t, wc).Status.Conditions, wfv1.ConditionLastRunSucceeded)
+			if condition == nil || condition.Status != tt.want || condition.Reason != tt.reason {
+				t.Errorf("got last run condition %+v, want %s with reason %s", condition, tt.want, tt.reason)
+			}
+		})
+	}
+}
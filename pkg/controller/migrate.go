@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//migrateRuns moves runs recorded on the workflow status by older versions to WorkflowRun objects
+func (c *controller) migrateRuns(ctx context.Context, wf *wfv1.Workflow) error {
+	if len(wf.Status.LegacyRuns) == 0 {
+		return nil
+	}
+
+	controller := true
+	for _, legacy := range wf.Status.LegacyRuns {
+		run := &wfv1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      wfv1.RunName(wf.Name, legacy.ID),
+				Namespace: wf.Namespace,
+				Labels: map[string]string{
+					"workflow": wf.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "trinity.cloudlego.com/v1",
+						Kind:       wfv1.Kind,
+						Name:       wf.Name,
+						UID:        wf.UID,
+						Controller: &controller,
+					},
+				},
+			},
+			Spec: wfv1.WorkflowRunSpec{
+				Workflow: wf.Name,
+			},
+		}
+		run.Kind = "WorkflowRun"
+		run.APIVersion = "trinity.cloudlego.com/v1"
+
+		created, err := c.wfclient.WorkFlowRuns(wf.Namespace).Create(ctx, run, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		created.Status = legacy
+		created.Kind = "WorkflowRun"
+		created.APIVersion = "trinity.cloudlego.com/v1"
+		_, err = c.wfclient.WorkFlowRuns(wf.Namespace).UpdateStatus(ctx, created, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+
+		if legacy.ID > wf.Status.TotalRuns {
+			wf.Status.TotalRuns = legacy.ID
+		}
+		if legacy.Finished() {
+			if legacy.Succeeded() {
+				wf.Status.SucceededRuns++
+			} else {
+				wf.Status.FailedRuns++
+			}
+		}
+	}
+
+	wf.Status.LegacyRuns = nil
+	wf.Kind = "Workflow"
+	wf.APIVersion = "trinity.cloudlego.com/v1"
+	updated, err := c.wfclient.WorkFlows(wf.Namespace).UpdateStatus(ctx, wf, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	*wf = *updated
+
+	c.log.Infof("Moved run history of workflow %s/%s to WorkflowRuns", wf.Namespace, wf.Name)
+	return nil
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+//pruneInterval is how often the run history of every workflow is checked against its retention limits
+const pruneInterval = time.Minute
+
+//pruneRuns enforces the run history limits and TTL of every workflow known to the informer
+func (c *controller) pruneRuns(ctx context.Context) {
+	workflows, err := c.lister.List(labels.Everything())
+	if err != nil {
+		c.log.WithError(err).Error("Failed to list workflows")
+		return
+	}
+	for _, wf := range workflows {
+		if wf.Spec.SuccessfulRunsHistoryLimit == nil && wf.Spec.FailedRunsHistoryLimit == nil && wf.Spec.RunTTL == nil {
+			continue
+		}
+
+		runs, err := c.wfclient.WorkFlowRuns(wf.Namespace).List(ctx, wfv1.RunsOf(wf.Name))
+		if err != nil {
+			c.log.WithError(err).Errorf("Failed to list runs of workflow %s/%s", wf.Namespace, wf.Name)
+			continue
+		}
+
+		for _, run := range expiredRuns(&wf.Spec, runs.Items, time.Now()) {
+			err := c.wfclient.WorkFlowRuns(wf.Namespace).Delete(ctx, run.Name, metav1.DeleteOptions{})
+			if err != nil {
+				c.log.WithError(err).Errorf("Failed to remove run %s of workflow %s/%s", run.Name, wf.Namespace, wf.Name)
+				continue
+			}
+			err = utils.DeleteRunJobs(ctx, c.client, wf.Name, wf.Namespace, run.Name)
+			if err != nil {
+				c.log.WithError(err).Errorf("Failed to remove jobs of run %s of workflow %s/%s", run.Name, wf.Namespace, wf.Name)
+			}
+			c.log.Infof("Removed run %s of workflow %s/%s", run.Name, wf.Namespace, wf.Name)
+		}
+	}
+}
+
+//expiredRuns returns the finished runs that exceed the history limits or are older than the TTL of a workflow.
+//Runs that are still in progress are never returned.
+func expiredRuns(spec *wfv1.WorkflowSpec, runs []wfv1.WorkflowRun, now time.Time) []wfv1.WorkflowRun {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Status.ID > runs[j].Status.ID
+	})
+
+	expired := []wfv1.WorkflowRun{}
+	succeeded, failed := 0, 0
+	for _, run := range runs {
+		if !run.Status.Finished() {
+			continue
+		}
+
+		if spec.RunTTL != nil && run.Status.EndedAt != nil {
+			if now.Sub(run.Status.EndedAt.Time) > spec.RunTTL.Duration {
+				expired = append(expired, run)
+				continue
+			}
+		}
+
+		if run.Status.Succeeded() {
+			succeeded++
+			if spec.SuccessfulRunsHistoryLimit != nil && succeeded > int(*spec.SuccessfulRunsHistoryLimit) {
+				expired = append(expired, run)
+			}
+		} else {
+			failed++
+			if spec.FailedRunsHistoryLimit != nil && failed > int(*spec.FailedRunsHistoryLimit) {
+				expired = append(expired, run)
+			}
+		}
+	}
+	return expired
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//testRun returns run id of a workflow in phase. Finished runs ended age before now.
+func testRun(id int, phase wfv1.RunPhase, now time.Time, age time.Duration) wfv1.WorkflowRun {
+	run := wfv1.WorkflowRun{ObjectMeta: metav1.ObjectMeta{Name: wfv1.RunName("wf1", id), Namespace: namespace}}
+	run.Status.ID = id
+	run.Status.Phase = phase
+	if phase.Final() {
+		ended := metav1.NewTime(now.Add(-age))
+		run.Status.EndedAt = &ended
+	}
+	return run
+}
+
+func TestExpiredRuns(t *testing.T) {
+	now := time.Now()
+	limit := func(n int32) *int32 { return &n }
+	ttl := &metav1.Duration{Duration: time.Hour}
+
+	tests := []struct {
+		name string
+		spec wfv1.WorkflowSpec
+		runs []wfv1.WorkflowRun
+		want []int
+	}{
+		{
+			name: "nil limits and nil TTL keep every run",
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunSucceeded, now, 48*time.Hour),
+				testRun(2, wfv1.RunFailed, now, 24*time.Hour),
+				testRun(3, wfv1.RunSucceeded, now, time.Minute),
+			},
+		},
+		{
+			name: "in-progress runs are never pruned",
+			spec: wfv1.WorkflowSpec{SuccessfulRunsHistoryLimit: limit(0), FailedRunsHistoryLimit: limit(0), RunTTL: &metav1.Duration{}},
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunPending, now, 0),
+				testRun(2, wfv1.RunRunning, now, 0),
+				testRun(3, wfv1.RunSucceeded, now, time.Minute),
+			},
+			want: []int{3},
+		},
+		{
+			name: "runs that ended longer ago than the TTL expire",
+			spec: wfv1.WorkflowSpec{RunTTL: ttl},
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunSucceeded, now, 3*time.Hour),
+				testRun(2, wfv1.RunFailed, now, 2*time.Hour),
+				testRun(3, wfv1.RunSucceeded, now, 30*time.Minute),
+				testRun(4, wfv1.RunRunning, now, 0),
+			},
+			want: []int{2, 1},
+		},
+		{
+			name: "successful and failed runs are limited separately",
+			spec: wfv1.WorkflowSpec{SuccessfulRunsHistoryLimit: limit(1), FailedRunsHistoryLimit: limit(2)},
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunFailed, now, 6*time.Minute),
+				testRun(2, wfv1.RunSucceeded, now, 5*time.Minute),
+				testRun(3, wfv1.RunError, now, 4*time.Minute),
+				testRun(4, wfv1.RunSucceeded, now, 3*time.Minute),
+				testRun(5, wfv1.RunCancelled, now, 2*time.Minute),
+				testRun(6, wfv1.RunSucceeded, now, time.Minute),
+			},
+			want: []int{4, 2, 1},
+		},
+		{
+			name: "a nil limit keeps every run of its kind",
+			spec: wfv1.WorkflowSpec{FailedRunsHistoryLimit: limit(1)},
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunSucceeded, now, 4*time.Minute),
+				testRun(2, wfv1.RunFailed, now, 3*time.Minute),
+				testRun(3, wfv1.RunSucceeded, now, 2*time.Minute),
+				testRun(4, wfv1.RunFailed, now, time.Minute),
+			},
+			want: []int{2},
+		},
+		{
+			name: "runs expired by the TTL do not count against the limits",
+			spec: wfv1.WorkflowSpec{SuccessfulRunsHistoryLimit: limit(1), RunTTL: ttl},
+			runs: []wfv1.WorkflowRun{
+				testRun(1, wfv1.RunSucceeded, now, 3*time.Hour),
+				testRun(2, wfv1.RunSucceeded, now, 2*time.Minute),
+				testRun(3, wfv1.RunSucceeded, now, time.Minute),
+			},
+			want: []int{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			for _, run := range expiredRuns(&tt.spec, tt.runs, now) {
+				got = append(got, run.Status.ID)
+			}
+			want := tt.want
+			if want == nil {
+				want = []int{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got expired runs %v, want %v", got, want)
+			}
+		})
+	}
+}
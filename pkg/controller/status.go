@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+//fieldManager is the name the controller applies the status of workflows under
+const fieldManager = "trinity-controller"
+
+//statusInterval is how often the schedule and last run conditions of every workflow are refreshed
+const statusInterval = 30 * time.Second
+
+//refreshStatus brings the status of every workflow known to the informer up to date with its CronJob and runs
+func (c *controller) refreshStatus(ctx context.Context) {
+	workflows, err := c.lister.List(labels.Everything())
+	if err != nil {
+		c.log.WithError(err).Error("Failed to list workflows")
+		return
+	}
+	for _, cached := range workflows {
+		wf := cached.DeepCopy()
+
+		//only the reconcile of the current generation may change the Ready condition
+		ready := meta.FindStatusCondition(wf.Status.Conditions, wfv1.ConditionReady)
+		if ready != nil && ready.Status == metav1.ConditionFalse && ready.Reason == "ReconcileFailed" {
+			continue
+		}
+
+		err = c.syncStatus(ctx, wf, nil)
+		if err != nil {
+			c.log.WithError(err).Errorf("Failed to update status of workflow %s/%s", wf.Namespace, wf.Name)
+		}
+	}
+}
+
+//syncStatus updates the conditions and schedule fields of a workflow. reconcileErr is the outcome of
+//the last attempt to set up the workflow.
+func (c *controller) syncStatus(ctx context.Context, wf *wfv1.Workflow, reconcileErr error) error {
+	status := wf.Status.DeepCopy()
+	now := time.Now()
+
+	scheduled := metav1.Condition{
+		Type:               wfv1.ConditionScheduled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: wf.Generation,
+		Reason:             "CronJobReady",
+		Message:            "scheduled with " + wf.Spec.Schedule,
+	}
+
+	sched, err := cron.ParseStandard(wf.Spec.Schedule)
+	if err != nil {
+		status.NextScheduleTime = nil
+		scheduled.Status = metav1.ConditionFalse
+		scheduled.Reason = "InvalidSchedule"
+		scheduled.Message = err.Error()
+	} else {
+		next := metav1.NewTime(sched.Next(now))
+		status.NextScheduleTime = &next
+	}
+
+	cronjob, err := utils.GetCron(ctx, c.client, wf.Name, wf.Namespace)
+	if err != nil {
+		if scheduled.Status == metav1.ConditionTrue {
+			scheduled.Status = metav1.ConditionFalse
+			scheduled.Reason = "CronJobMissing"
+			scheduled.Message = err.Error()
+		}
+	} else {
+		status.LastScheduleTime = cronjob.Status.LastScheduleTime
+		if scheduled.Status == metav1.ConditionTrue && cronjob.Spec.Schedule != wf.Spec.Schedule {
+			scheduled.Status = metav1.ConditionFalse
+			scheduled.Reason = "CronJobOutdated"
+			scheduled.Message = "cronjob is scheduled with " + cronjob.Spec.Schedule
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, scheduled)
+
+	ready := metav1.Condition{
+		Type:               wfv1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: wf.Generation,
+		Reason:             "Ready",
+		Message:            "workflow is ready to run",
+	}
+	if reconcileErr != nil {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ReconcileFailed"
+		ready.Message = reconcileErr.Error()
+	} else if scheduled.Status != metav1.ConditionTrue {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "NotScheduled"
+		ready.Message = scheduled.Message
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+
+	if reconcileErr == nil {
+		status.ObservedGeneration = wf.Generation
+	}
+
+	lastRun := metav1.Condition{
+		Type:               wfv1.ConditionLastRunSucceeded,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: wf.Generation,
+		Reason:             "NoFinishedRun",
+		Message:            "no run has finished yet",
+	}
+	var phase wfv1.RunPhase
+	if last := wf.Status.LastRun; last != nil && last.EndedAt != nil {
+		phase = last.Phase
+		if phase == "" {
+			//summaries written by older versions do not carry the outcome of the run. It is taken from the
+			//run while it is kept.
+			run, err := c.runLister.WorkflowRuns(wf.Namespace).Get(last.Name)
+			if err == nil {
+				phase = run.Status.Phase
+				status.LastRun.Phase = phase
+			}
+		}
+	}
+	switch {
+	case phase == wfv1.RunSucceeded:
+		last := wf.Status.LastRun
+		lastRun.Status = metav1.ConditionTrue
+		lastRun.Reason = "RunSucceeded"
+		lastRun.Message = "run " + last.Name + " succeeded"
+		if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(last.EndedAt) {
+			status.LastSuccessfulTime = last.EndedAt.DeepCopy()
+		}
+	case phase != "":
+		lastRun.Status = metav1.ConditionFalse
+		lastRun.Reason = "Run" + string(phase)
+		lastRun.Message = "run " + wf.Status.LastRun.Name + " finished with phase " + string(phase)
+	default:
+		//a run is in progress, or its outcome is unknown since it was pruned. Keep the outcome of the previous one.
+		if existing := meta.FindStatusCondition(status.Conditions, wfv1.ConditionLastRunSucceeded); existing != nil {
+			lastRun = *existing
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, lastRun)
+
+	if reflect.DeepEqual(status, &wf.Status) {
+		return nil
+	}
+
+	//only the fields maintained by the controller are applied, so runs finishing meanwhile are not overwritten
+	applied := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: wf.Name, Namespace: wf.Namespace},
+		Status: wfv1.WorkflowStatus{
+			ObservedGeneration: status.ObservedGeneration,
+			LastScheduleTime:   status.LastScheduleTime,
+			NextScheduleTime:   status.NextScheduleTime,
+			LastSuccessfulTime: status.LastSuccessfulTime,
+			Conditions:         status.Conditions,
+		},
+	}
+	_, err = c.wfclient.WorkFlows(wf.Namespace).ApplyStatus(ctx, applied, fieldManager)
+	if err != nil {
+		return err
+	}
+
+	if status.LastRun != nil && status.LastRun.Phase != wf.Status.LastRun.Phase {
+		patch := fmt.Sprintf(`{"status":{"lastRun":{"phase":%q}}}`, status.LastRun.Phase)
+		_, err = c.wfclient.WorkFlows(wf.Namespace).Patch(ctx, wf.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}, "status")
+		if err != nil {
+			return err
+		}
+	}
+
+	wf.Status = *status
+	return nil
+}
@@ -1,151 +1,387 @@
 package executor
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"unicode/utf8"
 
 	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
 	"github.com/arunprasadmudaliar/trinity/pkg/utils"
 	"github.com/sirupsen/logrus"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
-func Execute(config string, workflow string, namespace string, runid int, taskid int) {
+//TaskEnv is the environment variable the runner uses to hand the task definition to the executor
+const TaskEnv = "TRINITY_TASK"
 
-	var cfg *rest.Config
-	var err error
-	storageendpoint := workflow + "-artifact-svc." + namespace + ".svc.cluster.local"
+//ScriptEnv is the environment variable the runner fills from the Secret holding the script of a task. The
+//script is not part of the task definition, so it does not show in the spec of the task pod.
+const ScriptEnv = "TRINITY_SCRIPT"
 
-	if config == "" {
-		cfg, err = rest.InClusterConfig()
-		if err != nil {
-			logrus.Fatalf("error occured while reading incluster kubeconfig:%v", err)
-			//return nil, err
-		}
-	} else {
-		cfg, err = clientcmd.BuildConfigFromFlags("", config)
-		if err != nil {
-			logrus.WithError(err).Fatal("failed to build workflow client configuration")
-		}
-	}
+//TerminationLog is where the executor writes its Result for the runner to collect
+const TerminationLog = "/dev/termination-log"
 
-	kc, err := wfv1.NewForConfig(cfg)
-	if err != nil {
-		logrus.WithError(err).Fatal("failed to create workflow client for given configuration")
-	}
+//maxTerminationMessage is the size limit kubernetes applies to a container termination message
+const maxTerminationMessage = 4096
+
+//OutputLimitEnv is the environment variable holding the number of bytes of output kept in the task status
+const OutputLimitEnv = "TRINITY_OUTPUT_LIMIT"
+
+//InputRefEnv is set by the runner instead of WF_INPUT when the output of the previous task was offloaded
+//to the artifact store. It holds the key of the output in OutputBucket.
+const InputRefEnv = "WF_INPUT_REF"
+
+//MaxEnvInput is the largest value linux accepts for a single environment variable of a process.
+//Larger inputs are only available through WF_INPUT_FILE.
+const MaxEnvInput = 128*1024 - 1
+
+//OutputLogPrefix starts the line of the task log holding the full output, base64 encoded, when the output
+//did not fit the termination message and the artifact store is not used
+const OutputLogPrefix = "trinity-output:"
 
-	//result := wfv1.Workflow{}
-	wf, err := kc.WorkFlows(namespace).Get(workflow)
+//maxStderr is how much of the end of stderr is kept in the result
+const maxStderr = 1024
+
+//OutputBucket returns the artifact store bucket holding task outputs larger than the output limit
+func OutputBucket(workflow string) string {
+	return workflow + "-outputs"
+}
+
+//OutputKey returns the key the output of a task of a run is stored under in OutputBucket
+func OutputKey(run string, taskid int) string {
+	return run + "/task-" + strconv.Itoa(taskid)
+}
+
+//Result is the outcome of a task reported through the termination message of the task container
+type Result struct {
+	Status wfv1.TaskPhase `json:"status"`
+	Output string         `json:"output"`
+	//Stderr is the tail of what the task wrote to stderr, at most maxStderr bytes
+	Stderr string `json:"stderr"`
+	Error  string `json:"error"`
+	//ExitCode is the exit code of the task command or script
+	ExitCode int32 `json:"exitCode"`
+	//Truncated is set when Output holds only the part of the output that fits the termination message.
+	//OutputSize is the size of the full output.
+	Truncated  bool `json:"truncated,omitempty"`
+	OutputSize int  `json:"outputSize,omitempty"`
+	//OutputRef is the key of the full output in OutputBucket when it is larger than the output limit
+	OutputRef string `json:"outputRef,omitempty"`
+	//OutputInLog is set when the full output was written to the log of the task, see OutputFromLog
+	OutputInLog bool `json:"outputInLog,omitempty"`
+}
+
+//Options identify the task Execute runs
+type Options struct {
+	Workflow  string
+	Namespace string
+	//Run is the name of the WorkflowRun the task belongs to
+	Run    string
+	TaskID int
+	//Last is set for the last task of the workflow, which does not hand artifacts on
+	Last bool
+	//Log receives the messages of the executor
+	Log logrus.FieldLogger
+}
+
+//Execute runs a task of a run without access to the kubernetes API. The task definition is read from
+//TaskEnv, a script from a Secret from ScriptEnv, the output of the previous task from WF_INPUT, and the result is written to the termination
+//message and returned. A task that fails is reported like one that succeeds, an error is only returned
+//when the result could not be reported. Cancelling ctx stops the task.
+func Execute(ctx context.Context, opts Options) (*Result, error) {
+	log := utils.Logger(opts.Log)
+	workflow, taskid := opts.Workflow, opts.TaskID
+	storageendpoint := workflow + "-artifact-svc." + opts.Namespace + ".svc.cluster.local"
+
+	var task wfv1.Workflowtask
+	err := json.Unmarshal([]byte(os.Getenv(TaskEnv)), &task)
 	if err != nil {
-		logrus.WithError(err).Errorf("failed to get workflow %s", workflow)
+		log.WithError(err).Errorf("failed to read definition of task %d", taskid)
+		return report(ctx, log, Result{Status: wfv1.TaskError, Error: err.Error()}, opts, storageendpoint)
 	}
 
-	//Inject input variable
-	if taskid > 0 {
-		err := inputVar(wf.Status.Runs[runid].Tasks[taskid-1].Output)
-		if err != nil {
-			logrus.WithError(err).Errorf("failed to inject input for task %d", taskid)
+	//A script from a secret is handed over in ScriptEnv, which is not passed on to the script
+	if task.Command.ScriptFrom != nil {
+		script, ok := os.LookupEnv(ScriptEnv)
+		if !ok {
+			err = fmt.Errorf("script of task %s was not provided in %s", task.Name, ScriptEnv)
+			log.WithError(err).Errorf("failed to read definition of task %d", taskid)
+			return report(ctx, log, Result{Status: wfv1.TaskError, Error: err.Error()}, opts, storageendpoint)
 		}
+		os.Unsetenv(ScriptEnv)
+		task.Command.Script = script
+		task.Command.ScriptFrom = nil
+	}
+
+	//Download the output of the previous task if it was offloaded to the artifact store
+	if ref := os.Getenv(InputRefEnv); ref != "" {
+		err = loadInput(ctx, log, workflow, storageendpoint, ref)
 	} else {
-		logrus.Info("no need to inject input since this is first task")
+		err = provideInput(log, []byte(os.Getenv("WF_INPUT")))
+	}
+	if err != nil {
+		log.WithError(err).Errorf("failed to load input for task %d", taskid)
+		return report(ctx, log, Result{Status: wfv1.TaskError, Error: err.Error()}, opts, storageendpoint)
 	}
 
 	//Check if artifact store is used.If yes, download artifacts
 	if os.Getenv("MINIO_ROOT_USER") != "" {
 		if taskid > 0 {
-			err = utils.DownloadArtifacts(workflow, storageendpoint)
+			err = utils.DownloadArtifacts(ctx, workflow, storageendpoint)
 			if err != nil {
-				logrus.WithError(err).Info("failed to download artifacts")
+				log.WithError(err).Info("failed to download artifacts")
+			} else {
+				log.Info("artifacts were downloaded successfully")
 			}
-			logrus.Info("artifacts were downloaded successfully")
 		} else {
-			logrus.Info("no need to download artifacts since this is the first task")
+			log.Info("no need to download artifacts since this is the first task")
 		}
 	} else {
-		logrus.Info("skipping artifact download since artifact store is not used")
-	}
-
-	var output []byte
-
-	if wf.Spec.Tasks[taskid].Command.Script != "" {
-		output, err = execScript(wf.Spec.Tasks[taskid].Command.Script)
-	} else {
-		output, err = exec.Command(wf.Spec.Tasks[taskid].Command.Inline.Command, wf.Spec.Tasks[taskid].Command.Inline.Args...).Output()
+		log.Info("skipping artifact download since artifact store is not used")
 	}
 
-	//command := getCmd(wf.Spec.Tasks[taskid].Command)
-	//args := getArgs(wf.Spec.Tasks[taskid].Command, wf.Spec.Tasks[taskid].Args)
-	//c := exec.Command(command, args...)
-
-	var st string
-	wf.Kind = "Workflow"
-	wf.APIVersion = "trinity.cloudlego.com/v1"
-
-	var e string
-	//output, err := c.Output()
-	if err != nil {
-		st = "failed"
-		e = err.Error()
-	} else {
-		st = "success"
-		e = ""
-	}
+	result := RunTask(ctx, &task, ".", nil, os.Stdout)
 
 	//upload artifacts if artifact store is enabled. Skip for the last task.
 	if os.Getenv("MINIO_ROOT_USER") != "" {
-		if taskid != len(wf.Spec.Tasks)-1 {
-			artifacts := utils.ReadArtifactsFolder("outgoing")
-			if len(artifacts) > 0 {
-
-				err := utils.UploadArtifacts(workflow, storageendpoint, artifacts)
+		if !opts.Last {
+			artifacts, err := utils.ReadArtifactsFolder("outgoing")
+			if err != nil {
+				log.WithError(err).Errorf("failed to read artifacts")
+			} else if len(artifacts) > 0 {
+				err := utils.UploadArtifacts(ctx, workflow, storageendpoint, artifacts)
 				if err != nil {
-					logrus.WithError(err).Errorf("failed to upload artifacts")
+					log.WithError(err).Errorf("failed to upload artifacts")
+				} else {
+					log.Info("artifacts were uploaded successfully")
 				}
-				logrus.Info("artifacts were uploaded successfully")
 			} else {
-				logrus.Info("no artifacts to upload")
+				log.Info("no artifacts to upload")
 			}
 		} else {
-			logrus.Info("skipping artifacts upload since this is the last task")
+			log.Info("skipping artifacts upload since this is the last task")
+		}
+	} else {
+		log.Info("skipping artifact upload since artifact store is not used")
+	}
+
+	reported, err := report(ctx, log, result, opts, storageendpoint)
+	if err != nil {
+		return reported, err
+	}
+	log.Infof("reported result of task %s for workflow %s in namespace %s", task.Name, workflow, opts.Namespace)
+	return reported, nil
+}
+
+//RunTask runs the inline command or the script of a task in dir and returns its outcome. The script is
+//written to dir. The command gets env as its environment, or the environment of the process when env is nil.
+//Stdout and stderr are also written, interleaved as they are produced, to logs. Output is not truncated.
+func RunTask(ctx context.Context, task *wfv1.Workflowtask, dir string, env []string, logs io.Writer) Result {
+	var cmd *exec.Cmd
+	if task.Command.Script != "" {
+		script, err := filepath.Abs(filepath.Join(dir, "workflow.sh"))
+		if err != nil {
+			return Result{Status: wfv1.TaskError, Error: err.Error(), ExitCode: -1}
+		}
+		err = ioutil.WriteFile(script, []byte(task.Command.Script), 0777)
+		if err != nil {
+			return Result{Status: wfv1.TaskError, Error: err.Error(), ExitCode: -1}
 		}
+		cmd = exec.CommandContext(ctx, script)
 	} else {
-		logrus.Info("skipping artifact upload since artifact store is not used")
+		cmd = exec.CommandContext(ctx, task.Command.Inline.Command, task.Command.Inline.Args...)
 	}
+	cmd.Dir = dir
+	cmd.Env = env
 
-	taskstatus := wfv1.TaskStatus{
-		Name:   wf.Spec.Tasks[taskid].Name,
-		Status: st,
-		Output: string(output),
-		Error:  e,
+	output, stderr, err := run(cmd, logs)
+	result := Result{Status: wfv1.TaskSucceeded, Output: string(output), Stderr: Tail(string(stderr), maxStderr)}
+	if err != nil {
+		//a command that could not be started is an error, one that exited with a non-zero code a failure
+		result.Status = wfv1.TaskError
+		result.Error = err.Error()
+		result.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.Status = wfv1.TaskFailed
+			result.ExitCode = int32(exitErr.ExitCode())
+		}
+		//a task stopped by cancelling ctx did not fail by itself
+		if ctx.Err() != nil {
+			result.Status = wfv1.TaskError
+			result.Error = ctx.Err().Error()
+		}
 	}
+	return result
+}
 
-	if taskid == len(wf.Spec.Tasks)-1 {
-		wf.Status.Runs[runid].Phase = "completed"
-		wf.Status.Runs[runid].EndedAt = utils.Timestamp()
+//report writes the result to the termination message. Output larger than the output limit is uploaded to the
+//artifact store when it is used. Output too large for the termination message is truncated there, and written
+//to the log of the task when it was not uploaded, so the runner can still hand all of it to the next task.
+func report(ctx context.Context, log logrus.FieldLogger, result Result, opts Options, storageendpoint string) (*Result, error) {
+	if len(result.Output) > outputLimit() && os.Getenv("MINIO_ROOT_USER") != "" {
+		key := OutputKey(opts.Run, opts.TaskID)
+		err := utils.UploadOutput(ctx, OutputBucket(opts.Workflow), storageendpoint, key, []byte(result.Output))
+		if err != nil {
+			log.WithError(err).Error("failed to upload task output to artifact store")
+		} else {
+			result.OutputRef = key
+		}
 	}
 
-	wf.Status.Runs[runid].Tasks = append(wf.Status.Runs[runid].Tasks, taskstatus)
+	msg, _ := json.Marshal(result)
+	if len(msg) > maxTerminationMessage {
+		result.Truncated = true
+		result.OutputSize = len(result.Output)
+		if result.OutputRef == "" {
+			writeOutputToLog(os.Stdout, result.Output)
+			result.OutputInLog = true
+		}
+		result.Output = truncate(result, maxTerminationMessage)
+		msg, _ = json.Marshal(result)
+	}
 
-	_, err = kc.WorkFlows(namespace).Put(workflow, wf)
+	err := ioutil.WriteFile(TerminationLog, msg, 0644)
 	if err != nil {
-		logrus.WithError(err).Errorf("failed to update status for workflow %s in namespace %s", workflow, namespace)
+		return &result, fmt.Errorf("failed to write task result to termination log: %v", err)
 	}
+	return &result, nil
+}
 
-	logrus.Infof("updated status for workflow %s in namespace %s", workflow, namespace)
+//writeOutputToLog writes the full output of a task to its log on a line of its own starting with OutputLogPrefix
+func writeOutputToLog(w io.Writer, output string) {
+	fmt.Fprintf(w, "\n%s%s\n", OutputLogPrefix, base64.StdEncoding.EncodeToString([]byte(output)))
+}
 
+//OutputFromLog returns the full output of a task the executor wrote to the log of the task. The line written
+//last is used, since the task itself may have written lines that look alike before.
+func OutputFromLog(logs []byte) (string, error) {
+	var encoded []byte
+	found := false
+	for _, line := range bytes.Split(logs, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte(OutputLogPrefix)) {
+			encoded = bytes.TrimSpace(line[len(OutputLogPrefix):])
+			found = true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("output not found in the log of the task")
+	}
+	output, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode output in the log of the task: %v", err)
+	}
+	return string(output), nil
 }
 
-func inputVar(input string) error {
-	return os.Setenv("WF_INPUT", input)
+//outputLimit returns the number of bytes of output to keep in the task status
+func outputLimit() int {
+	limit, err := strconv.Atoi(os.Getenv(OutputLimitEnv))
+	if err != nil || limit < 0 {
+		return wfv1.DefaultOutputLimit
+	}
+	return limit
 }
 
-func execScript(script string) ([]byte, error) {
-	err := ioutil.WriteFile("./workflow.sh", []byte(script), 0777)
+//loadInput downloads the offloaded output of the previous task and provides it to the task
+func loadInput(ctx context.Context, log logrus.FieldLogger, workflow string, storageendpoint string, ref string) error {
+	creds := wfv1.MinioCreds{
+		AccessKey: os.Getenv("MINIO_ROOT_USER"),
+		SecretKey: os.Getenv("MINIO_ROOT_PASSWORD"),
+	}
+	input, err := utils.DownloadOutput(ctx, OutputBucket(workflow), storageendpoint, ref, creds)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	return provideInput(log, input)
+}
+
+//provideInput writes the input of the task to the file named by WF_INPUT_FILE and, when small enough to be
+//passed to a process, to WF_INPUT
+func provideInput(log logrus.FieldLogger, input []byte) error {
+	file := filepath.Join(os.TempDir(), "wf_input")
+	err := ioutil.WriteFile(file, input, 0644)
+	if err != nil {
+		return err
+	}
+	err = os.Setenv("WF_INPUT_FILE", file)
+	if err != nil {
+		return err
+	}
+
+	if len(input) > MaxEnvInput {
+		log.Warnf("input of %d bytes is too large for WF_INPUT, it is only available through WF_INPUT_FILE", len(input))
+		return os.Unsetenv("WF_INPUT")
+	}
+	return os.Setenv("WF_INPUT", string(input))
+}
+
+//truncate returns the tail of the output that keeps the encoded result within limit bytes
+func truncate(result Result, limit int) string {
+	output := result.Output
+	result.Output = ""
+	overhead, _ := json.Marshal(result)
+	for len(output) > 0 {
+		encoded, _ := json.Marshal(output)
+		if len(overhead)+len(encoded) <= limit {
+			break
+		}
+		cut := len(encoded) + len(overhead) - limit
+		if cut > len(output) {
+			cut = len(output)
+		}
+		output = output[runeStart(output, cut):]
+	}
+	return output
+}
+
+//run captures stdout and stderr of a command separately. Both are also written, interleaved as they
+//are produced, to logs.
+func run(cmd *exec.Cmd, logs io.Writer) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	if logs == nil {
+		logs = ioutil.Discard
+	}
+	combined := &syncWriter{w: logs}
+	cmd.Stdout = io.MultiWriter(&stdout, combined)
+	cmd.Stderr = io.MultiWriter(&stderr, combined)
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+//syncWriter serializes writes from the stdout and stderr of a command
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+//Tail returns at most the last n bytes of s. The cut is moved forward to the start of a rune, so that
+//multi-byte characters are not split.
+func Tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[runeStart(s, len(s)-n):]
+}
+
+//runeStart returns the first offset in s from i on that starts a rune
+func runeStart(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
 	}
-	cmd := exec.Command("./workflow.sh")
-	return cmd.Output()
+	return i
 }
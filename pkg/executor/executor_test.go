@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+)
+
+func TestOutputFromLog(t *testing.T) {
+	output := strings.Repeat("line of output\n", 1000) + OutputLogPrefix + "not the output"
+
+	var logs bytes.Buffer
+	logs.WriteString("task started\n")
+	logs.WriteString(output)
+	writeOutputToLog(&logs, output)
+	logs.WriteString("reported result of task\n")
+
+	got, err := OutputFromLog(logs.Bytes())
+	if err != nil {
+		t.Fatalf("OutputFromLog failed: %v", err)
+	}
+	if got != output {
+		t.Errorf("got output of %d bytes, want the %d bytes written to the log", len(got), len(output))
+	}
+
+	_, err = OutputFromLog([]byte("task started\n"))
+	if err == nil {
+		t.Error("OutputFromLog succeeded for a log without output")
+	}
+}
+
+func TestTailKeepsRunes(t *testing.T) {
+	s := strings.Repeat("é", 10)
+	for n := 0; n <= len(s)+1; n++ {
+		got := Tail(s, n)
+		if !utf8.ValidString(got) || len(got) > n || !strings.HasSuffix(s, got) || len(got) < n-1 {
+			t.Errorf("got tail %q of %d bytes, want the whole runes of the last %d bytes", got, len(got), n)
+		}
+	}
+}
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	result := Result{Status: wfv1.TaskSucceeded, Output: strings.Repeat("日本語", 1000)}
+	for _, limit := range []int{100, 101, 102, 1000} {
+		got := truncate(result, limit)
+		if !utf8.ValidString(got) || !strings.HasSuffix(result.Output, got) {
+			t.Errorf("got truncated output %q for limit %d, want the whole runes at the end of the output", got, limit)
+		}
+		result := result
+		result.Output = got
+		encoded, _ := json.Marshal(result)
+		if len(encoded) > limit {
+			t.Errorf("got result of %d bytes, want at most %d", len(encoded), limit)
+		}
+	}
+}
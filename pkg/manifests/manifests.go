@@ -0,0 +1,10 @@
+//Package manifests holds the manifests trinity is installed with that are generated from the Go types of the
+//API, so the manifests applied to a cluster match the binary that serves them
+package manifests
+
+//go:generate go run -C ../../hack ./generate ..
+
+//CRD returns the CustomResourceDefinitions of workflows and workflow runs, as found in deployments/crd.yaml
+func CRD() string {
+	return crd
+}
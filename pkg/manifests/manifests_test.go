@@ -0,0 +1,149 @@
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	wfv2 "github.com/arunprasadmudaliar/trinity/api/v2"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCRDMatchesDeployment(t *testing.T) {
+	data, err := ioutil.ReadFile("../../deployments/crd.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != CRD() {
+		t.Error("deployments/crd.yaml differs from the CRDs compiled into trinity, run go generate ./pkg/manifests")
+	}
+}
+
+//schemas returns the OpenAPI schema of every version of every CRD by name and version, e.g. workflows/v1
+func schemas(t *testing.T) map[string]map[string]interface{} {
+	out := map[string]map[string]interface{}{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(CRD())))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var crd struct {
+			Spec struct {
+				Names    struct{ Plural string }
+				Versions []struct {
+					Name   string
+					Schema struct {
+						OpenAPIV3Schema map[string]interface{}
+					}
+				}
+			}
+		}
+		err = yaml.Unmarshal(bytes.TrimSpace(doc), &crd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range crd.Spec.Versions {
+			out[crd.Spec.Names.Plural+"/"+v.Name] = v.Schema.OpenAPIV3Schema
+		}
+	}
+	return out
+}
+
+//jsonFields returns the JSON names of the fields of struct type t, including those of inlined structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			for n, ft := range jsonFields(f.Type) {
+				fields[n] = ft
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+//compare reports the fields of the Go type t that schema lacks and the properties of schema t lacks. Types
+//of other modules are expected to be generated right and are not descended into.
+func compare(t *testing.T, path string, typ reflect.Type, schema map[string]interface{}) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		items, _ := schema["items"].(map[string]interface{})
+		compare(t, path+"[]", typ.Elem(), items)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if !strings.HasPrefix(typ.PkgPath(), "github.com/arunprasadmudaliar/trinity/") {
+		return
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	fields := jsonFields(typ)
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s.%s of the Go type is missing in the CRD", path, name)
+			continue
+		}
+		compare(t, path+"."+name, fields[name], prop)
+	}
+	for name := range props {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("%s.%s of the CRD is missing in the Go type", path, name)
+		}
+	}
+}
+
+func TestCRDMatchesTypes(t *testing.T) {
+	all := schemas(t)
+	types := map[string]reflect.Type{
+		"workflows/v1":    reflect.TypeOf(wfv1.Workflow{}),
+		"workflows/v2":    reflect.TypeOf(wfv2.Workflow{}),
+		"workflowruns/v1": reflect.TypeOf(wfv1.WorkflowRun{}),
+	}
+	if len(all) != len(types) {
+		t.Errorf("got CRD versions %v, want %d", reflect.ValueOf(all).MapKeys(), len(types))
+	}
+	for version, typ := range types {
+		schema, ok := all[version]
+		if !ok {
+			t.Errorf("CRD version %s is missing", version)
+			continue
+		}
+		props := schema["properties"].(map[string]interface{})
+		for _, part := range []string{"spec", "status"} {
+			field, _ := typ.FieldByName(strings.Title(part))
+			compare(t, version+"."+part, field.Type, props[part].(map[string]interface{}))
+		}
+	}
+}
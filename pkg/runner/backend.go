@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"context"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+)
+
+//Backend executes the tasks of runs. JobBackend runs every task in a kubernetes Job, LocalBackend runs
+//tasks as processes on the machine of the runner.
+type Backend interface {
+	//Start prepares the execution of the tasks of a run, e.g. by deploying the artifact store
+	Start(ctx context.Context, run *wfv1.WorkflowRun) (Session, error)
+}
+
+//Session executes the tasks of a single run, one after another
+type Session interface {
+	//Execute runs a task and returns its status. Tasks that fail are reported in the status, an error is
+	//only returned when ctx is cancelled.
+	Execute(ctx context.Context, task Task) (wfv1.TaskStatus, error)
+	//Close releases what Start set up. It is called once the run is completed, also when ctx was cancelled.
+	Close()
+}
+
+//Task is a task of a run as handed to a Session
+type Task struct {
+	//ID is the index of the task in the spec of the run
+	ID int
+	//Definition is the task to execute, with a script from a ConfigMap resolved into Command.Script. A script
+	//from a Secret is left in Command.ScriptFrom for the task to read, so its content is not handed around.
+	Definition *wfv1.Workflowtask
+	//Previous is the status of the task before, whose output is the input of this task
+	Previous wfv1.TaskStatus
+	//Last is set for the last task of the run
+	Last bool
+}
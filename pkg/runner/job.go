@@ -0,0 +1,283 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/executor"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+//JobBackend runs every task in a kubernetes Job whose pod executes the task with trinity exec. When the
+//workflow stores artifacts, an artifact store is deployed for the duration of the run.
+type JobBackend struct {
+	kc  kubernetes.Interface
+	log logrus.FieldLogger
+	//Image is the image of the task pods
+	Image string
+}
+
+//NewJobBackend creates a JobBackend that creates jobs with kc
+func NewJobBackend(kc kubernetes.Interface, log logrus.FieldLogger) *JobBackend {
+	return &JobBackend{kc: kc, log: utils.Logger(log), Image: IMAGE}
+}
+
+//Start deploys the artifact store when the workflow of the run stores artifacts
+func (b *JobBackend) Start(ctx context.Context, run *wfv1.WorkflowRun) (Session, error) {
+	s := &jobSession{backend: b, run: run, previous: -1}
+	spec := run.Spec.WorkflowSpec
+
+	//deploy minio to store artifacts
+	if spec.StoreArtifacts {
+		s.creds = wfv1.MinioCreds{
+			AccessKey: utils.MinioCredential(),
+			SecretKey: utils.MinioCredential(),
+		}
+
+		var err error
+		s.minio, s.svc, err = utils.DeployMinio(ctx, b.kc, run.Spec.Workflow, run.Namespace, s.creds, spec.PodDefaults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize artifact store: %v", err)
+		}
+		b.log.Info("artifact store is up and running")
+	}
+	return s, nil
+}
+
+type jobSession struct {
+	backend *JobBackend
+	run     *wfv1.WorkflowRun
+	creds   wfv1.MinioCreds
+	minio   *v1.Pod
+	svc     *v1.Service
+	//output is the full output of the task executed last, which may be truncated in its status
+	output   string
+	previous int
+}
+
+//Close removes the artifact store
+func (s *jobSession) Close() {
+	if s.minio == nil {
+		return
+	}
+
+	//Perform cleanup of artifactory storage
+	ctx, cancel := cleanupContext()
+	defer cancel()
+	err := utils.DeleteMinio(ctx, s.backend.kc, s.minio, s.svc)
+	if err != nil {
+		s.backend.log.WithError(err).Errorf("failed to delete artifact store")
+		return
+	}
+	s.backend.log.Info("artifact store was removed successfully")
+}
+
+//Execute runs a task in a job and collects its result from the pod of the job
+func (s *jobSession) Execute(ctx context.Context, task Task) (wfv1.TaskStatus, error) {
+	b := s.backend
+	name, namespace := s.run.Spec.Workflow, s.run.Namespace
+	spec := s.run.Spec.WorkflowSpec
+
+	payload, _ := json.Marshal(task.Definition)
+	env := []v1.EnvVar{
+		{Name: executor.TaskEnv, Value: string(payload)},
+		{Name: executor.OutputLimitEnv, Value: strconv.Itoa(outputLimit(spec))},
+	}
+	//the script of a secret is read by the pod from the secret, so it does not show in the job
+	if src := task.Definition.Command.ScriptFrom; src != nil && src.SecretKeyRef != nil {
+		env = append(env, v1.EnvVar{Name: executor.ScriptEnv, ValueFrom: &v1.EnvVarSource{SecretKeyRef: src.SecretKeyRef.DeepCopy()}})
+	}
+	if task.Previous.OutputRef != "" {
+		env = append(env, v1.EnvVar{Name: executor.InputRefEnv, Value: strings.TrimPrefix(task.Previous.OutputRef, executor.OutputBucket(name)+"/")})
+	} else {
+		input, err := s.input(task)
+		if err != nil {
+			b.log.WithError(err).Errorf("failed to pass input to task %s", task.Definition.Name)
+			return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, ctx.Err()
+		}
+		env = append(env, v1.EnvVar{Name: "WF_INPUT", Value: input})
+	}
+
+	job, err := utils.CreateJob(ctx, b.kc, name, namespace, b.Image, s.run.Name, strconv.Itoa(task.ID), task.Last, s.creds, spec, task.Definition, env)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to create job for task %s", task.Definition.Name)
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, ctx.Err()
+	}
+	//the job is removed once its result is collected, or when the run is cancelled
+	defer b.removeJob(job)
+
+	status, err := b.waitForJob(ctx, name, job, task.Definition.Name)
+	s.output, s.previous = status.Output, task.ID
+	limitOutput(&status, outputLimit(spec))
+	return status, err
+}
+
+//input returns the output of the previous task for WF_INPUT. It is the full output, also when it was truncated
+//in the status of the previous task. Outputs too large for an environment variable can only be passed
+//through the artifact store.
+func (s *jobSession) input(task Task) (string, error) {
+	input := task.Previous.Output
+	if s.previous == task.ID-1 {
+		input = s.output
+	}
+	if len(input) > executor.MaxEnvInput {
+		return "", fmt.Errorf("input of %d bytes is too large for WF_INPUT, store artifacts to pass it through WF_INPUT_FILE", len(input))
+	}
+	return input, nil
+}
+
+//waitForJob waits for the job of a task to finish and collects the result of the task
+func (b *JobBackend) waitForJob(ctx context.Context, workflow string, job *batchv1.Job, task string) (wfv1.TaskStatus, error) {
+	ch, err := utils.WatchJob(ctx, b.kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to watch job %s", job.ObjectMeta.Name)
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, ctx.Err()
+	}
+	defer ch.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.log.Infof("stopping task %s since the run was cancelled", task)
+			return wfv1.TaskStatus{Status: wfv1.TaskError, Error: ctx.Err().Error()}, ctx.Err()
+		case event, ok := <-ch.ResultChan():
+			if !ok {
+				err = fmt.Errorf("watch of job %s ended before the job finished", job.ObjectMeta.Name)
+				b.log.WithError(err).Errorf("failed to execute task %s", task)
+				return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, nil
+			}
+
+			switch event.Type {
+			case watch.Added:
+				b.log.Infof("waiting for task %s to complete", task)
+			case watch.Modified:
+				object, ok := event.Object.(*batchv1.Job)
+				if !ok || object.Name != job.ObjectMeta.Name || len(object.Status.Conditions) == 0 {
+					continue
+				}
+				if object.Status.Conditions[0].Type == "Complete" {
+					b.log.Infof("completed task %s for workflow %s", task, workflow)
+					return b.collectResult(ctx, workflow, job), nil
+				}
+
+				newerr := errors.New(object.Status.Conditions[0].Message)
+				b.log.WithError(newerr).Errorf("failed to execute task %s", task)
+				status := b.collectResult(ctx, workflow, job)
+				if status.Error == "" {
+					status.Status = wfv1.TaskError
+					status.Error = newerr.Error()
+				}
+				return status, nil
+			}
+		}
+	}
+}
+
+//collectResult reads the result the executor wrote to the termination message of the task pod along with
+//the timing and placement of the pod. Output that did not fit the termination message is read from the log
+//of the pod when it was not offloaded to the artifact store. The output is returned in full.
+func (b *JobBackend) collectResult(ctx context.Context, workflow string, job *batchv1.Job) wfv1.TaskStatus {
+	pods, err := utils.GetJobPods(ctx, b.kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to collect result of job %s", job.ObjectMeta.Name)
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}
+	}
+	if len(pods) == 0 {
+		err = fmt.Errorf("no pod found for job %s", job.ObjectMeta.Name)
+		b.log.WithError(err).Errorf("failed to collect result of job %s", job.ObjectMeta.Name)
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}
+	}
+
+	pod := pods[len(pods)-1]
+	status := wfv1.TaskStatus{
+		PodName:  pod.Name,
+		NodeName: pod.Spec.NodeName,
+		Attempt:  int32(len(pods)),
+	}
+
+	var terminated *v1.ContainerStateTerminated
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil {
+			terminated = cs.State.Terminated
+		}
+	}
+	if terminated == nil {
+		status.Status = wfv1.TaskError
+		status.Error = "task container did not terminate"
+		return status
+	}
+
+	started, finished := terminated.StartedAt, terminated.FinishedAt
+	status.StartedAt = &started
+	status.FinishedAt = &finished
+	status.Duration = &metav1.Duration{Duration: finished.Sub(started.Time)}
+
+	var result executor.Result
+	err = json.Unmarshal([]byte(terminated.Message), &result)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to parse result of job %s", job.ObjectMeta.Name)
+		status.Status = wfv1.TaskError
+		status.Error = err.Error()
+		status.ExitCode = &terminated.ExitCode
+		return status
+	}
+
+	status.Status = result.Status
+	status.Output = result.Output
+	status.Stderr = result.Stderr
+	status.OutputTruncated = result.Truncated
+	status.OutputSize = result.OutputSize
+	if result.OutputInLog {
+		output, err := b.outputFromLog(ctx, pod)
+		if err != nil {
+			b.log.WithError(err).Errorf("failed to read output of job %s, keeping truncated output", job.ObjectMeta.Name)
+		} else {
+			status.Output = output
+			status.OutputTruncated = false
+			status.OutputSize = 0
+		}
+	}
+	if result.OutputRef != "" {
+		status.OutputRef = executor.OutputBucket(workflow) + "/" + result.OutputRef
+	}
+	status.Error = result.Error
+	status.ExitCode = &result.ExitCode
+	return status
+}
+
+//outputFromLog reads the full output of a task the executor wrote to the log of the task pod
+func (b *JobBackend) outputFromLog(ctx context.Context, pod v1.Pod) (string, error) {
+	logs, err := utils.GetPodLogs(ctx, b.kc, pod.Name, pod.Namespace)
+	if err != nil {
+		return "", err
+	}
+	return executor.OutputFromLog(logs)
+}
+
+//removeJob deletes the job of a task and its pod. It also runs after the run was cancelled.
+func (b *JobBackend) removeJob(job *batchv1.Job) {
+	ctx, cancel := cleanupContext()
+	defer cancel()
+
+	err := utils.DeleteJob(ctx, b.kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to remove job %s.Manual clean up required before next run.", job.ObjectMeta.Name)
+	}
+	err = utils.DeleteJobPod(ctx, b.kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
+	if err != nil {
+		b.log.WithError(err).Errorf("failed to remove pod for job %s.Manual clean up required before next run.", job.ObjectMeta.Name)
+	}
+
+	b.log.Info("jobs and corresponding pods were removed successfully")
+}
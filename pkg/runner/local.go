@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/executor"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//artifactsDir is where tasks find the artifacts of the previous task (incoming) and leave artifacts for
+//the next task (outgoing) when they run in a pod
+const artifactsDir = "/artifacts/"
+
+//LocalBackend runs tasks as processes on the machine of the runner, e.g. to try a workflow on a laptop or
+//in CI without a cluster. Every task runs in a directory of its own, and the /artifacts/ directory that
+//scripts and arguments refer to is mapped to an artifacts directory there. Artifacts are handed from task
+//to task through a directory shared by the tasks of a run, like the artifact store of a run in the cluster.
+//
+//Tasks get the environment of the runner along with the env of the workflow and the task. Variables
+//taken from ConfigMaps and Secrets are not set, and image, resources and secret mounts do not apply.
+type LocalBackend struct {
+	//Dir is where the directories of runs are created, the default directory for temporary files when empty
+	Dir string
+	//Output receives stdout and stderr of the tasks. They are discarded when it is nil.
+	Output io.Writer
+	//Keep leaves the directory of a run in place once the run is completed
+	Keep bool
+	log  logrus.FieldLogger
+}
+
+//NewLocalBackend creates a LocalBackend
+func NewLocalBackend(log logrus.FieldLogger) *LocalBackend {
+	return &LocalBackend{log: utils.Logger(log)}
+}
+
+//Start creates the directory of a run
+func (b *LocalBackend) Start(ctx context.Context, run *wfv1.WorkflowRun) (Session, error) {
+	root, err := ioutil.TempDir(b.Dir, run.Name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for run %s: %v", run.Name, err)
+	}
+	store := filepath.Join(root, "store")
+	err = os.Mkdir(store, 0755)
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to create artifact store for run %s: %v", run.Name, err)
+	}
+	b.log.Infof("executing tasks of run %s in %s", run.Name, root)
+	return &localSession{backend: b, run: run, root: root, store: store, previous: -1}, nil
+}
+
+type localSession struct {
+	backend *LocalBackend
+	run     *wfv1.WorkflowRun
+	root    string
+	//store holds the artifacts handed on by the tasks of the run
+	store string
+	//output is the full output of the task executed last, which may be truncated in its status
+	output   string
+	previous int
+}
+
+//Close removes the directory of the run unless the backend keeps it
+func (s *localSession) Close() {
+	if s.backend.Keep {
+		s.backend.log.Infof("kept directory %s of run %s", s.root, s.run.Name)
+		return
+	}
+	err := os.RemoveAll(s.root)
+	if err != nil {
+		s.backend.log.WithError(err).Errorf("failed to remove directory %s of run %s", s.root, s.run.Name)
+	}
+}
+
+//Execute runs a task as a process in a directory of its own
+func (s *localSession) Execute(ctx context.Context, task Task) (wfv1.TaskStatus, error) {
+	b := s.backend
+	spec := s.run.Spec.WorkflowSpec
+
+	dir := filepath.Join(s.root, "task-"+strconv.Itoa(task.ID))
+	artifacts := filepath.Join(dir, "artifacts") + string(filepath.Separator)
+	for _, d := range []string{"incoming", "outgoing"} {
+		err := os.MkdirAll(artifacts+d, 0755)
+		if err != nil {
+			return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, nil
+		}
+	}
+
+	if spec.StoreArtifacts && task.ID > 0 {
+		err := copyFiles(s.store, artifacts+"incoming")
+		if err != nil {
+			b.log.WithError(err).Info("failed to copy artifacts")
+		}
+	}
+
+	if task.Definition.Command.ScriptFrom != nil {
+		err := fmt.Errorf("task %s takes its script from a secret, which local runs do not read", task.Definition.Name)
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, nil
+	}
+
+	//the paths the task refers to are moved into the directory of the task
+	definition := task.Definition.DeepCopy()
+	definition.Command.Script = strings.ReplaceAll(definition.Command.Script, artifactsDir, artifacts)
+	for i, arg := range definition.Command.Inline.Args {
+		definition.Command.Inline.Args[i] = strings.ReplaceAll(arg, artifactsDir, artifacts)
+	}
+
+	env, err := s.environment(dir, task)
+	if err != nil {
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: err.Error()}, nil
+	}
+
+	started := metav1.Now()
+	result := executor.RunTask(ctx, definition, dir, env, b.Output)
+	finished := metav1.Now()
+	if ctx.Err() != nil {
+		return wfv1.TaskStatus{Status: wfv1.TaskError, Error: ctx.Err().Error()}, ctx.Err()
+	}
+
+	if spec.StoreArtifacts && !task.Last {
+		err = copyFiles(artifacts+"outgoing", s.store)
+		if err != nil {
+			b.log.WithError(err).Errorf("failed to store artifacts")
+		}
+	}
+
+	s.output, s.previous = result.Output, task.ID
+	status := wfv1.TaskStatus{
+		Status:     result.Status,
+		Output:     result.Output,
+		Stderr:     result.Stderr,
+		Error:      result.Error,
+		ExitCode:   &result.ExitCode,
+		StartedAt:  &started,
+		FinishedAt: &finished,
+		Duration:   &metav1.Duration{Duration: finished.Sub(started.Time)},
+		Attempt:    1,
+	}
+	limitOutput(&status, outputLimit(spec))
+	return status, nil
+}
+
+//reservedEnv holds the environment variables trinity sets for a task
+var reservedEnv = map[string]bool{
+	"WF_INPUT":           true,
+	"WF_INPUT_FILE":      true,
+	executor.InputRefEnv: true,
+	executor.TaskEnv:     true,
+	executor.ScriptEnv:   true,
+}
+
+//environment returns the environment of a task. Like in a pod, the full output of the previous task is
+//written to the file in WF_INPUT_FILE and passed in WF_INPUT when it fits an environment variable.
+func (s *localSession) environment(dir string, task Task) ([]string, error) {
+	var env []string
+	for _, e := range os.Environ() {
+		//variables set by trinity are not inherited from the environment of the runner
+		if !reservedEnv[strings.SplitN(e, "=", 2)[0]] {
+			env = append(env, e)
+		}
+	}
+	for _, e := range utils.MergeEnv(s.run.Spec.WorkflowSpec.Env, task.Definition.Env) {
+		if e.ValueFrom != nil {
+			s.backend.log.Warnf("environment variable %s of task %s is taken from the cluster and is not set", e.Name, task.Definition.Name)
+			continue
+		}
+		env = append(env, e.Name+"="+e.Value)
+	}
+
+	input := task.Previous.Output
+	if s.previous == task.ID-1 {
+		input = s.output
+	}
+	file := filepath.Join(dir, "wf_input")
+	err := ioutil.WriteFile(file, []byte(input), 0644)
+	if err != nil {
+		return nil, err
+	}
+	env = append(env, "WF_INPUT_FILE="+file)
+	if len(input) <= executor.MaxEnvInput {
+		env = append(env, "WF_INPUT="+input)
+	}
+	return env, nil
+}
+
+//copyFiles copies the files in src to dst. Directories in src are skipped like by the artifact store.
+func copyFiles(src string, dst string) error {
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(src, f.Name()))
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dst, f.Name()), content, f.Mode())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//RunLocal runs a workflow read from a manifest with backend, without a cluster. The run is recorded in
+//memory only and is returned like Run returns it. Scripts taken from ConfigMaps or Secrets cannot be read
+//without a cluster, so workflows using them are rejected.
+func RunLocal(ctx context.Context, wf *wfv1.Workflow, backend *LocalBackend, log logrus.FieldLogger) (*wfv1.WorkflowRun, error) {
+	for _, task := range wf.Spec.Tasks {
+		if task.Command.ScriptFrom != nil {
+			return nil, fmt.Errorf("task %s takes its script from the cluster, which local runs do not support", task.Name)
+		}
+	}
+	wf = wf.DeepCopy()
+	if wf.Namespace == "" {
+		wf.Namespace = metav1.NamespaceDefault
+	}
+
+	run := newRun(wf.Name, wf.Namespace, 1, wf)
+	err := startStatus(run, 1)
+	if err != nil {
+		return nil, err
+	}
+	r := NewForClients(nil, nil, backend, log)
+	return r.execute(ctx, &memoryRecorder{run: run}, wf.Name, wf.Namespace, run)
+}
+
+//memoryRecorder records a run that is kept in memory only
+type memoryRecorder struct {
+	run *wfv1.WorkflowRun
+}
+
+func (m *memoryRecorder) recordTask(ctx context.Context, namespace string, runname string, status wfv1.TaskStatus) error {
+	m.run.Status.Tasks = append(m.run.Status.Tasks, status)
+	return nil
+}
+
+func (m *memoryRecorder) completeRun(ctx context.Context, name string, namespace string, runname string, phase wfv1.RunPhase) (*wfv1.WorkflowRun, error) {
+	if phase == "" {
+		phase = m.run.Status.Outcome()
+	}
+	err := m.run.Status.SetPhase(phase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete run %s: %v", runname, err)
+	}
+	now := metav1.Now()
+	m.run.Status.EndedAt = &now
+	return m.run.DeepCopy(), nil
+}
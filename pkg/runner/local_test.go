@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/fake"
+	"github.com/arunprasadmudaliar/trinity/pkg/executor"
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+//newLocalRunner returns a runner executing tasks as processes in a temporary directory
+func newLocalRunner(t *testing.T, wf *wfv1.Workflow) (*Runner, string) {
+	dir, err := ioutil.TempDir("", "trinity-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	backend := NewLocalBackend(log)
+	backend.Dir = dir
+	return NewForClients(kubefake.NewSimpleClientset(), fake.NewSimpleClientset(wf), backend, log), dir
+}
+
+func TestLocalBackendRunsTasks(t *testing.T) {
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace},
+		Spec: sdk.NewWorkflow("wf1", namespace).StoreArtifacts().Env("GREETING", "hi").Task(
+			sdk.Inline("first", "sh", "-c", "printf hello; printf data > /artifacts/outgoing/a.txt"),
+			sdk.Script("second", "#!/bin/sh\nprintf \"$WF_INPUT $(cat /artifacts/incoming/a.txt) $GREETING $NAME\"").Env("NAME", "second"),
+		).Spec(),
+	}
+	r, dir := newLocalRunner(t, wf)
+	defer os.RemoveAll(dir)
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if run.Status.Phase != wfv1.RunSucceeded {
+		t.Errorf("got phase %q with tasks %+v, want %q", run.Status.Phase, run.Status.Tasks, wfv1.RunSucceeded)
+	}
+	if len(run.Status.Tasks) != 2 {
+		t.Fatalf("got %d task statuses, want 2", len(run.Status.Tasks))
+	}
+	if output := run.Status.Tasks[1].Output; output != "hello data hi second" {
+		t.Errorf("got output %q of the second task, want the input, the artifact and the env of the task", output)
+	}
+	if task := run.Status.Tasks[0]; task.ExitCode == nil || *task.ExitCode != 0 || task.Duration == nil || task.Attempt != 1 {
+		t.Errorf("got first task %+v, want exit code 0, a duration and one attempt", task)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("got %d entries in the run directory, want the run to be cleaned up", len(files))
+	}
+}
+
+func TestLocalBackendRecordsFailedTask(t *testing.T) {
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace},
+		Spec:       sdk.NewWorkflow("wf1", namespace).Task(sdk.Inline("fail", "sh", "-c", "echo boom >&2; exit 3")).Spec(),
+	}
+	r, dir := newLocalRunner(t, wf)
+	defer os.RemoveAll(dir)
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("a failing task must not fail Run: %v", err)
+	}
+
+	if run.Status.Phase != wfv1.RunFailed {
+		t.Errorf("got phase %q, want %q", run.Status.Phase, wfv1.RunFailed)
+	}
+	failed := run.Status.Tasks[0]
+	if failed.Status != wfv1.TaskFailed || failed.ExitCode == nil || *failed.ExitCode != 3 || failed.Stderr != "boom\n" {
+		t.Errorf("got failed task %+v, want phase %q with exit code 3 and stderr", failed, wfv1.TaskFailed)
+	}
+}
+
+func TestLocalBackendPassesLargeInputThroughFile(t *testing.T) {
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace},
+		Spec: sdk.NewWorkflow("wf1", namespace).Task(
+			sdk.Inline("first", "sh", "-c", fmt.Sprintf("head -c %d /dev/zero | tr '\\0' a", executor.MaxEnvInput+1)),
+			sdk.Script("second", "#!/bin/sh\nprintf \"${WF_INPUT-unset} $(wc -c < $WF_INPUT_FILE)\""),
+		).Spec(),
+	}
+	r, dir := newLocalRunner(t, wf)
+	defer os.RemoveAll(dir)
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := fmt.Sprintf("unset %d", executor.MaxEnvInput+1)
+	if output := run.Status.Tasks[1].Output; output != want {
+		t.Errorf("got output %q of the second task, want %q from the input file only", output, want)
+	}
+}
+
+func TestLocalBackendDoesNotInheritTaskEnv(t *testing.T) {
+	for _, name := range []string{"WF_INPUT", "WF_INPUT_FILE", executor.TaskEnv, executor.ScriptEnv} {
+		os.Setenv(name, "inherited")
+		defer os.Unsetenv(name)
+	}
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace},
+		Spec: sdk.NewWorkflow("wf1", namespace).Task(
+			sdk.Script("first", "#!/bin/sh\nprintf \"${WF_INPUT-unset} ${TRINITY_TASK-unset} ${TRINITY_SCRIPT-unset} $(cat $WF_INPUT_FILE)\""),
+		).Spec(),
+	}
+	r, dir := newLocalRunner(t, wf)
+	defer os.RemoveAll(dir)
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if output := run.Status.Tasks[0].Output; output != " unset unset " {
+		t.Errorf("got output %q, want an empty input and none of the variables of the runner", output)
+	}
+}
+
+func TestRunLocal(t *testing.T) {
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1"},
+		Spec:       sdk.NewWorkflow("wf1", "").Task(sdk.Inline("hello", "echo", "hello")).Spec(),
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	backend := NewLocalBackend(log)
+
+	run, err := RunLocal(context.Background(), wf, backend, log)
+	if err != nil {
+		t.Fatalf("RunLocal failed: %v", err)
+	}
+	if run.Status.Phase != wfv1.RunSucceeded || len(run.Status.Tasks) != 1 || run.Status.Tasks[0].Output != "hello\n" {
+		t.Errorf("got run status %+v, want the succeeded task", run.Status)
+	}
+
+	wf.Spec.Tasks = sdk.NewWorkflow("wf1", "").Task(sdk.ScriptFromConfigMap("script", "scripts", "run.sh")).Spec().Tasks
+	_, err = RunLocal(context.Background(), wf, backend, log)
+	if err == nil {
+		t.Errorf("RunLocal succeeded, want an error for a script from a configmap")
+	}
+}
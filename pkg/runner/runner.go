@@ -1,182 +1,409 @@
 package runner
 
 import (
-	"errors"
-	"strconv"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/executor"
 	"github.com/arunprasadmudaliar/trinity/pkg/utils"
-	batchv1 "k8s.io/api/batch/v1"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/sirupsen/logrus"
 )
 
-//Run will trigger the executor
-func Run(config string, name string, ns string) {
-	cfg, err := clientcmd.BuildConfigFromFlags("", config)
+//Runner starts runs of workflows and executes their tasks with a Backend, as jobs unless told otherwise
+type Runner struct {
+	kc      kubernetes.Interface
+	wc      wfv1.WorkFlowV1Interface
+	backend Backend
+	log     logrus.FieldLogger
+}
+
+//New creates a Runner for the cluster described by config
+func New(config *rest.Config, log logrus.FieldLogger) (*Runner, error) {
+	kc, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		logrus.WithError(err).Fatal("Failed to build workflow client configuration")
+		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+	wc, err := wfv1.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow client: %v", err)
+	}
+	return NewForClients(kc, wc, nil, log), nil
+}
+
+//NewForClients creates a Runner that uses the given clients, e.g. fake ones in tests, and executes tasks
+//with backend. Tasks run as jobs when backend is nil.
+func NewForClients(kc kubernetes.Interface, wc wfv1.WorkFlowV1Interface, backend Backend, log logrus.FieldLogger) *Runner {
+	log = utils.Logger(log)
+	if backend == nil {
+		backend = NewJobBackend(kc, log)
 	}
+	return &Runner{kc: kc, wc: wc, backend: backend, log: log}
+}
 
-	kc, err := wfv1.NewForConfig(cfg)
+//Run starts a run of a workflow with a new Runner and waits for it to finish
+func Run(ctx context.Context, config *rest.Config, name string, ns string, log logrus.FieldLogger) error {
+	r, err := New(config, log)
 	if err != nil {
-		logrus.WithError(err).Fatal("Failed to create workflow client for given configuration")
+		return err
 	}
+	_, err = r.Run(ctx, name, ns)
+	return err
+}
 
-	workflow, err := kc.WorkFlows(ns).Get(name)
+//Run starts a run of a workflow, executes its tasks one after another and returns the finished run.
+//Tasks that fail are recorded in the run and are not returned as an error. When ctx is cancelled the
+//task in progress is stopped, the run is recorded as cancelled and ctx.Err() is returned.
+func (r *Runner) Run(ctx context.Context, name string, ns string) (*wfv1.WorkflowRun, error) {
+	workflow, err := r.wc.WorkFlows(ns).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		logrus.Error(err)
+		return nil, fmt.Errorf("failed to get workflow %s under namespace %s: %v", name, ns, err)
 	}
 
-	var runid int
-	if len(workflow.Status.Runs) == 0 {
-		runid, _ = initialRun(kc, name, ns, workflow)
-	} else {
-		runid, _ = nextRun(kc, name, ns, workflow)
+	run, err := r.startRun(ctx, name, ns, workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start a run for workflow %s under namespace %s: %v", name, ns, err)
 	}
-	deployJob(config, name, ns, workflow, strconv.Itoa(runid))
+	return r.execute(ctx, r, name, ns, run)
 }
 
-func initialRun(kc *wfv1.WorkFlowClient, name string, namespace string, workflow *wfv1.Workflow) (int, error) {
-	init := wfv1.WorkflowStatus{
-		Runs: []wfv1.Workflowruns{
-			{
-				ID:        1,
-				Phase:     "Running",
-				Tasks:     []wfv1.TaskStatus{},
-				StartedAt: utils.Timestamp(),
-				EndedAt:   "",
-			},
-		},
+//recorder records the progress of a run while its tasks are executed. The Runner records runs in the
+//cluster, a memoryRecorder keeps a run that exists nowhere else.
+type recorder interface {
+	//recordTask appends the status of a task to a run
+	recordTask(ctx context.Context, namespace string, runname string, status wfv1.TaskStatus) error
+	//completeRun moves a run to its final phase, or to the outcome of its tasks when phase is empty
+	completeRun(ctx context.Context, name string, namespace string, runname string, phase wfv1.RunPhase) (*wfv1.WorkflowRun, error)
+}
+
+//maxRunIDAttempts is how many IDs startRun tries when runs started at the same time claim the same ID
+const maxRunIDAttempts = 10
+
+//startRun creates a WorkflowRun owned by the workflow and records it as the last run of the workflow.
+//The run holds a copy of the spec of the workflow, so editing the workflow does not affect runs in progress.
+//The ID of the run is claimed by creating the WorkflowRun, whose name is unique, so runs started at the
+//same time get different IDs.
+func (r *Runner) startRun(ctx context.Context, name string, namespace string, workflow *wfv1.Workflow) (*wfv1.WorkflowRun, error) {
+	id := workflow.Status.TotalRuns + 1
+	//runs recorded by older versions may not have been moved to WorkflowRuns yet
+	if len(workflow.Status.LegacyRuns) >= id {
+		id = len(workflow.Status.LegacyRuns) + 1
 	}
 
-	workflow.Status = init
-	workflow.Kind = "Workflow"
-	workflow.APIVersion = "trinity.cloudlego.com/v1"
-	_, err := kc.WorkFlows(namespace).Put(name, workflow)
+	run := newRun(name, namespace, id, workflow)
+	created, err := r.wc.WorkFlowRuns(namespace).Create(ctx, run, metav1.CreateOptions{})
+	for attempt := 1; apierrors.IsAlreadyExists(err) && attempt < maxRunIDAttempts; attempt++ {
+		id++
+		run.Name = wfv1.RunName(name, id)
+		created, err = r.wc.WorkFlowRuns(namespace).Create(ctx, run, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	//status is a subresource and is ignored on create
+	err = startStatus(created, id)
 	if err != nil {
-		return -1, err
+		return nil, err
+	}
+	created.Kind = "WorkflowRun"
+	created.APIVersion = "trinity.cloudlego.com/v1"
+	created, err = r.wc.WorkFlowRuns(namespace).UpdateStatus(ctx, created, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
 	}
-	logrus.Infof("triggered run %d for workflow %s under namespace %s", 1, name, namespace)
-	return 0, nil
-}
 
-func nextRun(kc *wfv1.WorkFlowClient, name string, namespace string, workflow *wfv1.Workflow) (int, error) {
+	err = r.updateWorkflowStatus(ctx, name, namespace, func(status *wfv1.WorkflowStatus) {
+		if id > status.TotalRuns {
+			status.TotalRuns = id
+		}
+		//a run started later may already have recorded itself
+		if status.LastRun == nil || status.LastRun.ID <= id {
+			status.LastRun = summary(created)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	runid := len(workflow.Status.Runs)
+	r.log.Infof("triggered run %s for workflow %s under namespace %s", created.Name, name, namespace)
+	return created, nil
+}
 
-	runstatus := wfv1.Workflowruns{
-		ID:        runid + 1,
-		Phase:     "running",
+//newRun returns run id of a workflow, owned by the workflow and holding a copy of its spec
+func newRun(name string, namespace string, id int, workflow *wfv1.Workflow) *wfv1.WorkflowRun {
+	controller := true
+	run := &wfv1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      wfv1.RunName(name, id),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"workflow": name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "trinity.cloudlego.com/v1",
+					Kind:       wfv1.Kind,
+					Name:       name,
+					UID:        workflow.UID,
+					Controller: &controller,
+				},
+			},
+		},
+		Spec: wfv1.WorkflowRunSpec{
+			Workflow:           name,
+			WorkflowGeneration: workflow.Generation,
+			SpecHash:           workflow.Spec.Hash(),
+			WorkflowSpec:       workflow.Spec.DeepCopy(),
+		},
+	}
+	run.Kind = "WorkflowRun"
+	run.APIVersion = "trinity.cloudlego.com/v1"
+	return run
+}
+
+//startStatus sets the status of a run that starts now
+func startStatus(run *wfv1.WorkflowRun, id int) error {
+	now := metav1.Now()
+	run.Status = wfv1.Workflowruns{
+		ID:        id,
 		Tasks:     []wfv1.TaskStatus{},
-		StartedAt: utils.Timestamp(),
-		EndedAt:   "",
+		StartedAt: &now,
 	}
+	return run.Status.SetPhase(wfv1.RunRunning)
+}
 
-	workflow.Status.Runs = append(workflow.Status.Runs, runstatus)
-	workflow.Kind = "Workflow"
-	workflow.APIVersion = "trinity.cloudlego.com/v1"
-	_, err := kc.WorkFlows(namespace).Put(name, workflow)
+//outputLimit returns the number of bytes of task output kept in the run status of a workflow
+func outputLimit(spec *wfv1.WorkflowSpec) int {
+	if spec.OutputLimit != nil {
+		return int(*spec.OutputLimit)
+	}
+	return wfv1.DefaultOutputLimit
+}
 
-	if err != nil {
-		return -1, err
+//limitOutput keeps at most the last limit bytes of the output of a task in its status. The full output is
+//handed to the next task by the session.
+func limitOutput(status *wfv1.TaskStatus, limit int) {
+	if len(status.Output) <= limit {
+		return
+	}
+	if !status.OutputTruncated {
+		status.OutputTruncated = true
+		status.OutputSize = len(status.Output)
+	}
+	status.Output = executor.Tail(status.Output, limit)
+}
+
+func summary(run *wfv1.WorkflowRun) *wfv1.RunSummary {
+	return &wfv1.RunSummary{
+		Name:      run.Name,
+		ID:        run.Status.ID,
+		Phase:     run.Status.Phase,
+		StartedAt: run.Status.StartedAt,
+		EndedAt:   run.Status.EndedAt,
 	}
-	logrus.Infof("triggered run %d for workflow %s under namespace %s", runid+1, name, namespace)
-	return runid, nil
 }
 
-func deployJob(cfg string, name string, namespace string, workflow *wfv1.Workflow, runid string) {
+//cleanupTimeout bounds the calls that clean up after a task or run. They are made even when the context
+//of the run was cancelled.
+const cleanupTimeout = 30 * time.Second
 
-	kc, err := utils.Client(cfg)
+//cleanupContext returns a context for cleaning up that is not cancelled along with the context of the run
+func cleanupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cleanupTimeout)
+}
+
+//execute runs the tasks of the spec snapshot of a run one after another with the backend of the runner
+//and completes the run. The progress of the run is recorded with rec.
+func (r *Runner) execute(ctx context.Context, rec recorder, name string, namespace string, run *wfv1.WorkflowRun) (*wfv1.WorkflowRun, error) {
+	session, err := r.backend.Start(ctx, run)
 	if err != nil {
-		logrus.Error(err)
+		return r.abortRun(ctx, rec, name, namespace, run.Name, err)
 	}
+	//the session is closed once the run is completed
+	defer session.Close()
 
-	var minio *v1.Pod
-	var svc *v1.Service
-	var artifactEnabled bool
-	var creds wfv1.MinioCreds
-	//deploy minio to store artifacts
-	if workflow.Spec.StoreArtifacts {
-		artifactEnabled = true
+	spec := run.Spec.WorkflowSpec
+	//previous holds the status of the previous task, whose output is the input of the next task
+	var previous wfv1.TaskStatus
+	for taskid := range spec.Tasks {
+		task := spec.Tasks[taskid].DeepCopy()
 
-		creds = wfv1.MinioCreds{
-			AccessKey: utils.MinioCredential(),
-			SecretKey: utils.MinioCredential(),
+		//scripts from configmaps are resolved here since tasks have no API access. Scripts from secrets are
+		//only looked up, the task reads them from the secret.
+		var script *wfv1.ScriptRef
+		var resolveErr error
+		if src := task.Command.ScriptFrom; src != nil {
+			var content string
+			content, script, resolveErr = r.resolveScript(ctx, namespace, src)
+			if src.SecretKeyRef == nil {
+				task.Command.Script = content
+				task.Command.ScriptFrom = nil
+			}
 		}
 
-		minio, svc, err = utils.DeployMinio(kc, name, namespace, creds)
-		if err != nil {
-			logrus.WithError(err).Errorf("failed to initialize artifact store")
+		var status wfv1.TaskStatus
+		if resolveErr != nil {
+			if ctx.Err() != nil {
+				return r.abortRun(ctx, rec, name, namespace, run.Name, resolveErr)
+			}
+			r.log.WithError(resolveErr).Errorf("failed to read script for task %s", task.Name)
+			status = wfv1.TaskStatus{Status: wfv1.TaskError, Error: resolveErr.Error()}
+		} else {
+			r.log.Infof("executing task %s for workflow %s", task.Name, name)
+			status, err = session.Execute(ctx, Task{ID: taskid, Definition: task, Previous: previous, Last: taskid == len(spec.Tasks)-1})
+			if err != nil {
+				return r.abortRun(ctx, rec, name, namespace, run.Name, err)
+			}
 		}
+		status.Name = task.Name
+		status.Script = script
+		if !status.Status.Final() {
+			r.log.Errorf("task %s of run %s reported phase %q, recording it as %s", status.Name, run.Name, status.Status, wfv1.TaskError)
+			status.Status = wfv1.TaskError
+		}
+		previous = status
 
-		logrus.Info("artifact store is up and running")
+		err = rec.recordTask(ctx, namespace, run.Name, status)
+		if err != nil {
+			return r.abortRun(ctx, rec, name, namespace, run.Name, err)
+		}
 	}
+	return rec.completeRun(ctx, name, namespace, run.Name, "")
+}
 
-	for taskid, task := range workflow.Spec.Tasks {
-
-		//image := getImage((task.Command))
-		job, err := utils.CreateJob(kc, name, namespace, IMAGE, runid, strconv.Itoa(taskid), creds)
+//recordTask appends the status of a task to a run
+func (r *Runner) recordTask(ctx context.Context, namespace string, runname string, status wfv1.TaskStatus) error {
+	//the task is appended with a JSON patch, which does not depend on the version of the run it is applied to
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": "/status/tasks/-", "value": status},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode status of task %s: %v", status.Name, err)
+	}
+	_, err = r.wc.WorkFlowRuns(namespace).Patch(ctx, runname, types.JSONPatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to update status for run %s in namespace %s: %v", runname, namespace, err)
+	}
+	r.log.Infof("updated status for run %s in namespace %s", runname, namespace)
+	return nil
+}
 
+//completeRun moves a run to its final phase and updates the summary on the workflow. An empty phase
+//completes the run with the outcome of its tasks.
+func (r *Runner) completeRun(ctx context.Context, name string, namespace string, runname string, phase wfv1.RunPhase) (*wfv1.WorkflowRun, error) {
+	var run *wfv1.WorkflowRun
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var err error
+		run, err = r.wc.WorkFlowRuns(namespace).Get(ctx, runname, metav1.GetOptions{})
 		if err != nil {
-			logrus.Error(err)
+			return err
 		}
-
-		logrus.Infof("executing task %s for workflow %s", task.Name, name)
-		//ch, err := utils.WatchJob(kc, name+"-task-"+strconv.Itoa(taskid), namespace)
-		ch, err := utils.WatchJob(kc, name+"-task-"+strconv.Itoa(taskid), namespace)
+		next := phase
+		if next == "" {
+			next = run.Status.Outcome()
+		}
+		err = run.Status.SetPhase(next)
 		if err != nil {
-			logrus.Error(err)
-		}
-
-		for event := range ch.ResultChan() {
-			if event.Type == watch.Modified {
-				object := event.Object.(*batchv1.Job)
-
-				if len(object.Status.Conditions) > 0 {
-					if object.Status.Conditions[0].Type == "Complete" {
-						logrus.Infof("completed task %s for workflow %s", task.Name, name)
-						//removeJob(kc, name+"-task-"+strconv.Itoa(taskid), namespace)
-						removeJob(kc, job)
-					} else {
-						newerr := errors.New(object.Status.Conditions[0].Message)
-						logrus.WithError(newerr).Errorf("failed to execute task %s", name)
-						//removeJob(kc, name+"-task-"+strconv.Itoa(taskid), namespace)
-						removeJob(kc, job)
-					}
-					break
-				}
-			} else if event.Type == watch.Added {
-				logrus.Infof("waiting for task %s to complete", task.Name)
-			}
+			r.log.WithError(err).Errorf("failed to complete run %s", runname)
 		}
+		now := metav1.Now()
+		run.Status.EndedAt = &now
+		run.Kind = "WorkflowRun"
+		run.APIVersion = "trinity.cloudlego.com/v1"
+		run, err = r.wc.WorkFlowRuns(namespace).UpdateStatus(ctx, run, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete run %s in namespace %s: %v", runname, namespace, err)
 	}
 
-	//Perform cleanup of artifactory storage
-	if artifactEnabled {
-		err := utils.DeleteMinio(kc, minio, svc)
-		if err != nil {
-			logrus.WithError(err).Errorf("failed to delete artifact store")
+	err = r.updateWorkflowStatus(ctx, name, namespace, func(wf *wfv1.WorkflowStatus) {
+		if run.Status.Succeeded() {
+			wf.SucceededRuns++
+		} else {
+			wf.FailedRuns++
 		}
-		logrus.Info("artifact store was removed successfully")
+		if wf.LastRun == nil || wf.LastRun.ID <= run.Status.ID {
+			wf.LastRun = summary(run)
+		}
+	})
+	if err != nil {
+		return run, fmt.Errorf("failed to update status for workflow %s in namespace %s: %v", name, namespace, err)
 	}
+	r.log.Infof("updated status for workflow %s in namespace %s", name, namespace)
+	return run, nil
 }
 
-//func removeJob(kc *kubernetes.Clientset, name string, namespace string) {
-func removeJob(kc *kubernetes.Clientset, job *batchv1.Job) {
-	err := utils.DeleteJob(kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
-	if err != nil {
-		logrus.WithError(err).Errorf("failed to remove job %s.Manual clean up required before next run.", job.ObjectMeta.Name)
+//abortRun completes a run that could not execute all of its tasks, as cancelled when ctx was cancelled
+//and as an error otherwise. The run is returned along with the reason it was aborted.
+func (r *Runner) abortRun(ctx context.Context, rec recorder, name string, namespace string, runname string, reason error) (*wfv1.WorkflowRun, error) {
+	phase := wfv1.RunError
+	if ctx.Err() != nil {
+		phase = wfv1.RunCancelled
+		reason = ctx.Err()
 	}
-	err = utils.DeleteJobPod(kc, job.ObjectMeta.Name, job.ObjectMeta.Namespace)
+	r.log.WithError(reason).Errorf("aborting run %s of workflow %s in namespace %s", runname, name, namespace)
+
+	cctx, cancel := cleanupContext()
+	defer cancel()
+	run, err := rec.completeRun(cctx, name, namespace, runname, phase)
 	if err != nil {
-		logrus.WithError(err).Errorf("failed to remove pod for job %s.Manual clean up required before next run.", job.ObjectMeta.Name)
+		r.log.WithError(err).Errorf("failed to record run %s as %s", runname, phase)
 	}
+	return run, reason
+}
 
-	logrus.Info("jobs and corresponding pods were removed successfully")
+//updateWorkflowStatus applies update to the latest status of a workflow. The update is retried on the
+//latest version of the workflow when the workflow was changed in the meantime, e.g. by an overlapping run.
+func (r *Runner) updateWorkflowStatus(ctx context.Context, name string, namespace string, update func(*wfv1.WorkflowStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		wf, err := r.wc.WorkFlows(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(&wf.Status)
+		wf.Kind = "Workflow"
+		wf.APIVersion = "trinity.cloudlego.com/v1"
+		_, err = r.wc.WorkFlows(namespace).UpdateStatus(ctx, wf, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+//resolveScript reads the script referenced by src and records where it came from. A script in a Secret is
+//only checked to exist and is not returned, so it is not copied into the job of the task.
+func (r *Runner) resolveScript(ctx context.Context, namespace string, src *wfv1.ScriptSource) (string, *wfv1.ScriptRef, error) {
+	switch {
+	case src.ConfigMapKeyRef != nil:
+		ref := src.ConfigMapKeyRef
+		cm, err := utils.GetConfigMap(ctx, r.kc, ref.Name, namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		content, ok := cm.Data[ref.Key]
+		if !ok {
+			return "", nil, fmt.Errorf("key %s not found in configmap %s", ref.Key, ref.Name)
+		}
+		return content, &wfv1.ScriptRef{Kind: "ConfigMap", Name: ref.Name, Key: ref.Key, ResourceVersion: cm.ResourceVersion}, nil
+	case src.SecretKeyRef != nil:
+		ref := src.SecretKeyRef
+		secret, err := utils.GetSecret(ctx, r.kc, ref.Name, namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := secret.Data[ref.Key]; !ok {
+			return "", nil, fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+		}
+		return "", &wfv1.ScriptRef{Kind: "Secret", Name: ref.Name, Key: ref.Key, ResourceVersion: secret.ResourceVersion}, nil
+	}
+	return "", nil, fmt.Errorf("scriptFrom requires either configMapKeyRef or secretKeyRef")
 }
@@ -0,0 +1,405 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/client/fake"
+	"github.com/arunprasadmudaliar/trinity/pkg/executor"
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const namespace = "default"
+
+func testWorkflow(tasks ...*sdk.TaskBuilder) *wfv1.Workflow {
+	wf := &wfv1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf1", Namespace: namespace, UID: "wf1-uid", Generation: 2},
+		Spec:       sdk.NewWorkflow("wf1", namespace).Task(tasks...).Spec(),
+	}
+	return wf
+}
+
+func newTestRunner(objects ...*wfv1.Workflow) (*Runner, *kubefake.Clientset, *fake.Clientset) {
+	kc := kubefake.NewSimpleClientset()
+	wc := fake.NewSimpleClientset()
+	for _, obj := range objects {
+		wc.Tracker().Create(wfv1.GroupVersion.WithResource("workflows"), obj, obj.Namespace)
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	return NewForClients(kc, wc, nil, log), kc, wc
+}
+
+//finishJobs plays the part of the kubelet and the executor. Once the runner watches a job, the job is
+//finished with the result given for it: a pod carrying the result in its termination message is created
+//and the job is marked complete, or failed when the task did not succeed.
+func finishJobs(t *testing.T, kc *kubefake.Clientset, results map[string]executor.Result) {
+	kc.PrependWatchReactor("jobs", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := kc.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		name, _ := action.(k8stesting.WatchAction).GetWatchRestrictions().Fields.RequiresExactMatch("metadata.name")
+		result, ok := results[name]
+		if !ok {
+			t.Errorf("unexpected job %s", name)
+			return true, w, nil
+		}
+		go finishJob(t, kc, action.GetNamespace(), name, result)
+		return true, w, nil
+	})
+}
+
+func finishJob(t *testing.T, kc *kubefake.Clientset, ns string, name string, result executor.Result) {
+	ctx := context.Background()
+	job, err := kc.BatchV1().Jobs(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("failed to get job %s: %v", name, err)
+		return
+	}
+
+	msg, _ := json.Marshal(result)
+	now := metav1.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name + "-pod",
+			Namespace:         ns,
+			Labels:            map[string]string{"job-name": name},
+			CreationTimestamp: now,
+		},
+		Spec: corev1.PodSpec{NodeName: "node1"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   result.ExitCode,
+					Message:    string(msg),
+					StartedAt:  now,
+					FinishedAt: now,
+				}},
+			}},
+		},
+	}
+	_, err = kc.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		t.Errorf("failed to create pod of job %s: %v", name, err)
+		return
+	}
+
+	condition := batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}
+	if result.Status != wfv1.TaskSucceeded {
+		condition = batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}
+	}
+	job.Status.Conditions = append(job.Status.Conditions, condition)
+	_, err = kc.BatchV1().Jobs(ns).UpdateStatus(ctx, job, metav1.UpdateOptions{})
+	if err != nil {
+		t.Errorf("failed to finish job %s: %v", name, err)
+	}
+}
+
+//createdJobs returns the jobs the runner created, in order
+func createdJobs(kc *kubefake.Clientset) []*batchv1.Job {
+	var jobs []*batchv1.Job
+	for _, action := range kc.Actions() {
+		if create, ok := action.(k8stesting.CreateActionImpl); ok && action.GetResource().Resource == "jobs" {
+			jobs = append(jobs, create.GetObject().(*batchv1.Job))
+		}
+	}
+	return jobs
+}
+
+func envValue(job *batchv1.Job, name string) (string, bool) {
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+func getWorkflow(t *testing.T, wc *fake.Clientset) *wfv1.Workflow {
+	wf, err := wc.WorkFlows(namespace).Get(context.Background(), "wf1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get workflow: %v", err)
+	}
+	return wf
+}
+
+func TestStartRun(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("hello", "echo", "hello"))
+	r, _, wc := newTestRunner(wf)
+
+	run, err := r.startRun(context.Background(), wf.Name, namespace, wf)
+	if err != nil {
+		t.Fatalf("startRun failed: %v", err)
+	}
+
+	if run.Name != "wf1-run-1" || run.Status.ID != 1 {
+		t.Errorf("got run %s with ID %d, want wf1-run-1 with ID 1", run.Name, run.Status.ID)
+	}
+	if run.Status.Phase != wfv1.RunRunning || run.Status.StartedAt == nil {
+		t.Errorf("got phase %q and start %v, want a started run in phase %q", run.Status.Phase, run.Status.StartedAt, wfv1.RunRunning)
+	}
+	if run.Labels["workflow"] != "wf1" {
+		t.Errorf("got labels %v, want the workflow label", run.Labels)
+	}
+	if len(run.OwnerReferences) != 1 || run.OwnerReferences[0].UID != wf.UID {
+		t.Errorf("got owner references %v, want the workflow", run.OwnerReferences)
+	}
+	if run.Spec.WorkflowGeneration != 2 || run.Spec.SpecHash != wf.Spec.Hash() || len(run.Spec.WorkflowSpec.Tasks) != 1 {
+		t.Errorf("got spec %+v, want a snapshot of generation 2", run.Spec)
+	}
+
+	status := getWorkflow(t, wc).Status
+	if status.TotalRuns != 1 || status.LastRun == nil || status.LastRun.Name != "wf1-run-1" {
+		t.Errorf("got total runs %d and last run %+v, want run wf1-run-1 recorded", status.TotalRuns, status.LastRun)
+	}
+}
+
+func TestStartRunSkipsTakenIDs(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("hello", "echo", "hello"))
+	r, _, wc := newTestRunner(wf)
+
+	//a run started at the same time already claimed the next ID
+	taken := &wfv1.WorkflowRun{ObjectMeta: metav1.ObjectMeta{Name: "wf1-run-1", Namespace: namespace}}
+	_, err := wc.WorkFlowRuns(namespace).Create(context.Background(), taken, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := r.startRun(context.Background(), wf.Name, namespace, wf)
+	if err != nil {
+		t.Fatalf("startRun failed: %v", err)
+	}
+	if run.Name != "wf1-run-2" || run.Status.ID != 2 {
+		t.Errorf("got run %s with ID %d, want wf1-run-2 with ID 2", run.Name, run.Status.ID)
+	}
+	if status := getWorkflow(t, wc).Status; status.TotalRuns != 2 {
+		t.Errorf("got total runs %d, want 2", status.TotalRuns)
+	}
+}
+
+func TestRunExecutesTasksInOrder(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("first", "echo", "hello"), sdk.Script("second", "cat"))
+	r, kc, wc := newTestRunner(wf)
+	finishJobs(t, kc, map[string]executor.Result{
+		"wf1-run-1-task-0": {Status: wfv1.TaskSucceeded, Output: "hello"},
+		"wf1-run-1-task-1": {Status: wfv1.TaskSucceeded, Output: "hello world"},
+	})
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if run.Status.Phase != wfv1.RunSucceeded || run.Status.EndedAt == nil {
+		t.Errorf("got phase %q and end %v, want a finished run in phase %q", run.Status.Phase, run.Status.EndedAt, wfv1.RunSucceeded)
+	}
+	if len(run.Status.Tasks) != 2 {
+		t.Fatalf("got %d task statuses, want 2", len(run.Status.Tasks))
+	}
+	for i, want := range []struct{ name, output string }{{"first", "hello"}, {"second", "hello world"}} {
+		task := run.Status.Tasks[i]
+		if task.Name != want.name || task.Status != wfv1.TaskSucceeded || task.Output != want.output {
+			t.Errorf("got task %d %s in phase %q with output %q, want %s succeeded with output %q", i, task.Name, task.Status, task.Output, want.name, want.output)
+		}
+		if task.PodName == "" || task.NodeName != "node1" || task.Attempt != 1 {
+			t.Errorf("got pod %q on node %q attempt %d for task %d, want the pod of the job", task.PodName, task.NodeName, task.Attempt, i)
+		}
+	}
+
+	jobs := createdJobs(kc)
+	if len(jobs) != 2 {
+		t.Fatalf("got %d jobs, want 2", len(jobs))
+	}
+	if input, _ := envValue(jobs[1], "WF_INPUT"); input != "hello" {
+		t.Errorf("got WF_INPUT %q for the second task, want the output of the first", input)
+	}
+	if _, ok := envValue(jobs[0], executor.TaskEnv); !ok {
+		t.Errorf("job of the first task has no %s", executor.TaskEnv)
+	}
+	for _, job := range jobs {
+		_, err := kc.BatchV1().Jobs(namespace).Get(context.Background(), job.Name, metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("job %s was not removed: %v", job.Name, err)
+		}
+	}
+
+	status := getWorkflow(t, wc).Status
+	if status.SucceededRuns != 1 || status.FailedRuns != 0 {
+		t.Errorf("got %d succeeded and %d failed runs, want 1 succeeded", status.SucceededRuns, status.FailedRuns)
+	}
+	if status.LastRun == nil || status.LastRun.Phase != wfv1.RunSucceeded {
+		t.Errorf("got last run %+v, want a succeeded run", status.LastRun)
+	}
+}
+
+func TestRunRecordsFailedTask(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("fail", "false"), sdk.Inline("after", "true"))
+	r, kc, wc := newTestRunner(wf)
+	finishJobs(t, kc, map[string]executor.Result{
+		"wf1-run-1-task-0": {Status: wfv1.TaskFailed, ExitCode: 1, Error: "exit status 1", Stderr: "boom"},
+		"wf1-run-1-task-1": {Status: wfv1.TaskSucceeded},
+	})
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("a failing task must not fail Run: %v", err)
+	}
+
+	if run.Status.Phase != wfv1.RunFailed {
+		t.Errorf("got phase %q, want %q", run.Status.Phase, wfv1.RunFailed)
+	}
+	failed := run.Status.Tasks[0]
+	if failed.Status != wfv1.TaskFailed || failed.ExitCode == nil || *failed.ExitCode != 1 || failed.Stderr != "boom" {
+		t.Errorf("got failed task %+v, want phase %q with exit code 1 and stderr", failed, wfv1.TaskFailed)
+	}
+
+	status := getWorkflow(t, wc).Status
+	if status.SucceededRuns != 0 || status.FailedRuns != 1 {
+		t.Errorf("got %d succeeded and %d failed runs, want 1 failed", status.SucceededRuns, status.FailedRuns)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("sleep", "sleep", "3600"))
+	r, kc, _ := newTestRunner(wf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	//the job never finishes, the run is cancelled once the runner waits for it
+	kc.PrependWatchReactor("jobs", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := kc.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		cancel()
+		return true, w, err
+	})
+
+	run, err := r.Run(ctx, "wf1", namespace)
+	if err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if run == nil || run.Status.Phase != wfv1.RunCancelled || run.Status.EndedAt == nil {
+		t.Fatalf("got run %+v, want a finished run in phase %q", run, wfv1.RunCancelled)
+	}
+	if len(run.Status.Tasks) != 0 {
+		t.Errorf("got task statuses %+v, want none for the stopped task", run.Status.Tasks)
+	}
+	_, err = kc.BatchV1().Jobs(namespace).Get(context.Background(), "wf1-run-1-task-0", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("job of the stopped task was not removed: %v", err)
+	}
+}
+
+func TestRunKeepsSecretScriptOutOfJob(t *testing.T) {
+	wf := testWorkflow(sdk.ScriptFromSecret("deploy", "scripts", "deploy.sh"))
+	r, kc, _ := newTestRunner(wf)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "scripts", Namespace: namespace, ResourceVersion: "7"},
+		Data:       map[string][]byte{"deploy.sh": []byte("echo s3cr3t")},
+	}
+	_, err := kc.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	finishJobs(t, kc, map[string]executor.Result{
+		"wf1-run-1-task-0": {Status: wfv1.TaskSucceeded},
+	})
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if ref := run.Status.Tasks[0].Script; ref == nil || ref.Kind != "Secret" || ref.ResourceVersion != "7" {
+		t.Errorf("got script reference %+v, want the secret", ref)
+	}
+
+	jobs := createdJobs(kc)
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, want 1", len(jobs))
+	}
+	raw, _ := json.Marshal(jobs[0])
+	if strings.Contains(string(raw), "s3cr3t") {
+		t.Errorf("job of the task contains the script of the secret: %s", raw)
+	}
+	var source *corev1.EnvVarSource
+	for _, env := range jobs[0].Spec.Template.Spec.Containers[0].Env {
+		if env.Name == executor.ScriptEnv {
+			source = env.ValueFrom
+		}
+	}
+	if source == nil || source.SecretKeyRef == nil || source.SecretKeyRef.Name != "scripts" || source.SecretKeyRef.Key != "deploy.sh" {
+		t.Errorf("got %s from %+v, want it taken from the secret", executor.ScriptEnv, source)
+	}
+}
+
+func TestRunPassesFullOutputWithoutArtifacts(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("first", "cat", "big.txt"), sdk.Script("second", "cat"))
+	r, kc, _ := newTestRunner(wf)
+	output := strings.Repeat("a", 1000) + strings.Repeat("b", wfv1.DefaultOutputLimit)
+	finishJobs(t, kc, map[string]executor.Result{
+		"wf1-run-1-task-0": {Status: wfv1.TaskSucceeded, Output: output},
+		"wf1-run-1-task-1": {Status: wfv1.TaskSucceeded},
+	})
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	first := run.Status.Tasks[0]
+	if first.Output != strings.Repeat("b", wfv1.DefaultOutputLimit) || !first.OutputTruncated || first.OutputSize != len(output) {
+		t.Errorf("got output of %d bytes, truncated %v, size %d, want the last %d bytes of %d", len(first.Output), first.OutputTruncated, first.OutputSize, wfv1.DefaultOutputLimit, len(output))
+	}
+	jobs := createdJobs(kc)
+	if len(jobs) != 2 {
+		t.Fatalf("got %d jobs, want 2", len(jobs))
+	}
+	if input, _ := envValue(jobs[1], "WF_INPUT"); input != output {
+		t.Errorf("got WF_INPUT of %d bytes for the second task, want the full output of %d bytes", len(input), len(output))
+	}
+}
+
+func TestRunFailsTaskWithInputTooLargeForEnv(t *testing.T) {
+	wf := testWorkflow(sdk.Inline("first", "cat", "big.txt"), sdk.Script("second", "cat"))
+	r, kc, _ := newTestRunner(wf)
+	finishJobs(t, kc, map[string]executor.Result{
+		"wf1-run-1-task-0": {Status: wfv1.TaskSucceeded, Output: strings.Repeat("a", executor.MaxEnvInput+1)},
+	})
+
+	run, err := r.Run(context.Background(), "wf1", namespace)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if second := run.Status.Tasks[1]; second.Status != wfv1.TaskError || !strings.Contains(second.Error, "too large") {
+		t.Errorf("got second task %+v, want it in error for its input", second)
+	}
+	if jobs := createdJobs(kc); len(jobs) != 1 {
+		t.Errorf("got %d jobs, want none for the task whose input cannot be passed", len(jobs))
+	}
+}
+
+func TestLimitOutputKeepsRunes(t *testing.T) {
+	output := strings.Repeat("€", wfv1.DefaultOutputLimit)
+	status := wfv1.TaskStatus{Output: output}
+	limitOutput(&status, wfv1.DefaultOutputLimit)
+
+	if !utf8.ValidString(status.Output) || len(status.Output) > wfv1.DefaultOutputLimit || !strings.HasSuffix(output, status.Output) {
+		t.Errorf("got output of %d bytes ending in %q, want whole runes within %d bytes", len(status.Output), status.Output[len(status.Output)-3:], wfv1.DefaultOutputLimit)
+	}
+	if !status.OutputTruncated || status.OutputSize != len(output) {
+		t.Errorf("got truncated %v and size %d, want the size of the full output %d", status.OutputTruncated, status.OutputSize, len(output))
+	}
+}
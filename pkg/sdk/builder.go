@@ -0,0 +1,206 @@
+//Package sdk builds workflows in Go and submits them to a cluster running trinity
+package sdk
+
+import (
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//DefaultSchedule is the schedule the CRD defaults a workflow to when none is set
+const DefaultSchedule = wfv1.DefaultSchedule
+
+//WorkflowBuilder builds a Workflow. Its methods return the builder so calls can be chained.
+type WorkflowBuilder struct {
+	workflow wfv1.Workflow
+}
+
+//NewWorkflow starts building a workflow with the given name and namespace
+func NewWorkflow(name string, namespace string) *WorkflowBuilder {
+	b := &WorkflowBuilder{}
+	b.workflow.Name = name
+	b.workflow.Namespace = namespace
+	b.workflow.APIVersion = wfv1.GroupVersion.String()
+	b.workflow.Kind = wfv1.Kind
+	b.workflow.Spec.Schedule = DefaultSchedule
+	return b
+}
+
+//Labels adds labels to the workflow
+func (b *WorkflowBuilder) Labels(labels map[string]string) *WorkflowBuilder {
+	if b.workflow.Labels == nil {
+		b.workflow.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		b.workflow.Labels[k] = v
+	}
+	return b
+}
+
+//Schedule sets the cron schedule the workflow runs on
+func (b *WorkflowBuilder) Schedule(schedule string) *WorkflowBuilder {
+	b.workflow.Spec.Schedule = schedule
+	return b
+}
+
+//StoreArtifacts enables the artifact store, so tasks can hand files to the tasks after them
+func (b *WorkflowBuilder) StoreArtifacts() *WorkflowBuilder {
+	b.workflow.Spec.StoreArtifacts = true
+	return b
+}
+
+//Env sets an environment variable for every task
+func (b *WorkflowBuilder) Env(name string, value string) *WorkflowBuilder {
+	b.workflow.Spec.Env = append(b.workflow.Spec.Env, corev1.EnvVar{Name: name, Value: value})
+	return b
+}
+
+//EnvFrom adds environment variables from a ConfigMap or Secret to every task
+func (b *WorkflowBuilder) EnvFrom(source corev1.EnvFromSource) *WorkflowBuilder {
+	b.workflow.Spec.EnvFrom = append(b.workflow.Spec.EnvFrom, source)
+	return b
+}
+
+//SecretMount mounts a Secret in every task
+func (b *WorkflowBuilder) SecretMount(secret string, path string) *WorkflowBuilder {
+	b.workflow.Spec.SecretMounts = append(b.workflow.Spec.SecretMounts, wfv1.SecretMount{SecretName: secret, MountPath: path})
+	return b
+}
+
+//ServiceAccount sets the service account the runner and task pods run under
+func (b *WorkflowBuilder) ServiceAccount(name string) *WorkflowBuilder {
+	b.workflow.Spec.ServiceAccountName = name
+	return b
+}
+
+//PodDefaults sets the resources and scheduling constraints of the pods of the workflow
+func (b *WorkflowBuilder) PodDefaults(options wfv1.PodOptions) *WorkflowBuilder {
+	b.workflow.Spec.PodDefaults = &options
+	return b
+}
+
+//OutputLimit sets the number of bytes of a task's output kept in the run status
+func (b *WorkflowBuilder) OutputLimit(limit int32) *WorkflowBuilder {
+	b.workflow.Spec.OutputLimit = &limit
+	return b
+}
+
+//HistoryLimits sets the number of successful and failed runs to keep
+func (b *WorkflowBuilder) HistoryLimits(successful int32, failed int32) *WorkflowBuilder {
+	b.workflow.Spec.SuccessfulRunsHistoryLimit = &successful
+	b.workflow.Spec.FailedRunsHistoryLimit = &failed
+	return b
+}
+
+//RunTTL sets how long a finished run is kept
+func (b *WorkflowBuilder) RunTTL(ttl time.Duration) *WorkflowBuilder {
+	b.workflow.Spec.RunTTL = &metav1.Duration{Duration: ttl}
+	return b
+}
+
+//Task appends tasks to the workflow. Tasks run in the order they are added.
+func (b *WorkflowBuilder) Task(tasks ...*TaskBuilder) *WorkflowBuilder {
+	for _, t := range tasks {
+		b.workflow.Spec.Tasks = append(b.workflow.Spec.Tasks, *t.task.DeepCopy())
+	}
+	return b
+}
+
+//Spec returns a copy of the spec built so far without validating it
+func (b *WorkflowBuilder) Spec() wfv1.WorkflowSpec {
+	return *b.workflow.Spec.DeepCopy()
+}
+
+//Build validates the workflow and returns a copy of it
+func (b *WorkflowBuilder) Build() (*wfv1.Workflow, error) {
+	if errs := Validate(&b.workflow); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return b.workflow.DeepCopy(), nil
+}
+
+//TaskBuilder builds a task of a workflow
+type TaskBuilder struct {
+	task wfv1.Workflowtask
+}
+
+//Inline returns a task running a single command
+func Inline(name string, command string, args ...string) *TaskBuilder {
+	t := &TaskBuilder{}
+	t.task.Name = name
+	t.task.Command.Inline.Command = command
+	t.task.Command.Inline.Args = args
+	return t
+}
+
+//Script returns a task running a shell script
+func Script(name string, script string) *TaskBuilder {
+	t := &TaskBuilder{}
+	t.task.Name = name
+	t.task.Command.Script = script
+	return t
+}
+
+//ScriptFromConfigMap returns a task running the script stored under key in a ConfigMap
+func ScriptFromConfigMap(name string, configmap string, key string) *TaskBuilder {
+	t := &TaskBuilder{}
+	t.task.Name = name
+	t.task.Command.ScriptFrom = &wfv1.ScriptSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configmap},
+			Key:                  key,
+		},
+	}
+	return t
+}
+
+//ScriptFromSecret returns a task running the script stored under key in a Secret
+func ScriptFromSecret(name string, secret string, key string) *TaskBuilder {
+	t := &TaskBuilder{}
+	t.task.Name = name
+	t.task.Command.ScriptFrom = &wfv1.ScriptSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+			Key:                  key,
+		},
+	}
+	return t
+}
+
+//Env sets an environment variable for the task, overriding one set for the workflow
+func (t *TaskBuilder) Env(name string, value string) *TaskBuilder {
+	t.task.Env = append(t.task.Env, corev1.EnvVar{Name: name, Value: value})
+	return t
+}
+
+//EnvFrom adds environment variables from a ConfigMap or Secret to the task
+func (t *TaskBuilder) EnvFrom(source corev1.EnvFromSource) *TaskBuilder {
+	t.task.EnvFrom = append(t.task.EnvFrom, source)
+	return t
+}
+
+//SecretMount mounts a Secret in the task
+func (t *TaskBuilder) SecretMount(secret string, path string) *TaskBuilder {
+	t.task.SecretMounts = append(t.task.SecretMounts, wfv1.SecretMount{SecretName: secret, MountPath: path})
+	return t
+}
+
+//Resources sets the resource requests and limits of the task container
+func (t *TaskBuilder) Resources(resources corev1.ResourceRequirements) *TaskBuilder {
+	t.task.Resources = &resources
+	return t
+}
+
+//NodeSelector restricts the nodes the task pod can run on
+func (t *TaskBuilder) NodeSelector(selector map[string]string) *TaskBuilder {
+	t.task.NodeSelector = selector
+	return t
+}
+
+//Tolerations sets the tolerations of the task pod
+func (t *TaskBuilder) Tolerations(tolerations ...corev1.Toleration) *TaskBuilder {
+	t.task.Tolerations = tolerations
+	return t
+}
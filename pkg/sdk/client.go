@@ -0,0 +1,144 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+//PollInterval is how often Trigger and WaitForRun check on the cluster
+var PollInterval = 2 * time.Second
+
+//Client submits workflows and runs them
+type Client struct {
+	Kube      kubernetes.Interface
+	Workflows wfv1.WorkFlowV1Interface
+}
+
+//NewForConfig creates a Client for the cluster described by config
+func NewForConfig(config *rest.Config) (*Client, error) {
+	kc, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	wc, err := wfv1.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Kube: kc, Workflows: wc}, nil
+}
+
+//Submit validates a workflow and creates it, or replaces the spec of the workflow with the same name
+func (c *Client) Submit(ctx context.Context, wf *wfv1.Workflow) (*wfv1.Workflow, error) {
+	if errs := Validate(wf); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	workflows := c.Workflows.WorkFlows(wf.Namespace)
+	created, err := workflows.Create(ctx, wf, metav1.CreateOptions{})
+	if !apierrors.IsAlreadyExists(err) {
+		return created, err
+	}
+
+	existing, err := workflows.Get(ctx, wf.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	existing.Spec = *wf.Spec.DeepCopy()
+	existing.Labels = wf.Labels
+	return workflows.Update(ctx, existing, metav1.UpdateOptions{})
+}
+
+//Trigger starts a run of a workflow right away, the same way its schedule does. It returns the number
+//of runs the workflow had before, to be passed to WaitForRun. Trigger waits for the controller to have
+//set up a newly submitted workflow.
+func (c *Client) Trigger(ctx context.Context, namespace string, name string) (int, error) {
+	wf, err := c.Workflows.WorkFlows(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	cronName := "wf-cron-" + name
+	var template *batchv1.JobSpec
+	var owner metav1.OwnerReference
+	err = wait.PollImmediateUntil(PollInterval, func() (bool, error) {
+		cron, err := c.Kube.BatchV1beta1().CronJobs(namespace).Get(ctx, cronName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		template = cron.Spec.JobTemplate.Spec.DeepCopy()
+		controller := true
+		owner = metav1.OwnerReference{
+			APIVersion: "batch/v1beta1",
+			Kind:       "CronJob",
+			Name:       cron.Name,
+			UID:        cron.UID,
+			Controller: &controller,
+		}
+		return true, nil
+	}, ctx.Done())
+	if err != nil {
+		return 0, fmt.Errorf("workflow %s/%s is not scheduled: %v", namespace, name, err)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: cronName + "-manual-",
+			Namespace:    namespace,
+			Annotations: map[string]string{
+				"cronjob.kubernetes.io/instantiate": "manual",
+			},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: *template,
+	}
+	_, err = c.Kube.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return wf.Status.TotalRuns, nil
+}
+
+//WaitForRun waits for the first run of a workflow with an ID above after to finish and returns it
+func (c *Client) WaitForRun(ctx context.Context, namespace string, name string, after int) (*wfv1.WorkflowRun, error) {
+	var run *wfv1.WorkflowRun
+	err := wait.PollImmediateUntil(PollInterval, func() (bool, error) {
+		runs, err := c.Workflows.WorkFlowRuns(namespace).List(ctx, wfv1.RunsOf(name))
+		if err != nil {
+			return false, err
+		}
+		run = nil
+		for i := range runs.Items {
+			r := &runs.Items[i]
+			if r.Status.ID > after && (run == nil || r.Status.ID < run.Status.ID) {
+				run = r
+			}
+		}
+		return run != nil && run.Status.Finished(), nil
+	}, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+//Run triggers a run of a workflow and waits for it to finish. The run is returned whether it succeeded
+//or not, check its phase for the outcome.
+func (c *Client) Run(ctx context.Context, namespace string, name string) (*wfv1.WorkflowRun, error) {
+	after, err := c.Trigger(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.WaitForRun(ctx, namespace, name, after)
+}
@@ -0,0 +1,439 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	wfv2 "github.com/arunprasadmudaliar/trinity/api/v2"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+//Severities of diagnostics, named like the levels of SARIF
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+//Rules diagnostics are reported under
+const (
+	//RuleSyntax reports manifests that are not valid YAML
+	RuleSyntax = "syntax"
+	//RuleSchema reports fields the Workflow type does not have and values of the wrong type
+	RuleSchema = "schema"
+	//RuleRequired, RuleInvalid and RuleDuplicate report what Validate finds
+	RuleRequired  = "required"
+	RuleInvalid   = "invalid"
+	RuleDuplicate = "duplicate"
+	//RuleReference reports scripts taken from ConfigMaps and Secrets that are not in the linted manifests
+	RuleReference = "reference"
+)
+
+//Diagnostic is a problem found in a manifest, located by file and line
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	//Field is the path of the field the problem was found in, e.g. spec.tasks[1].command
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Field != "" {
+		msg = d.Field + ": " + msg
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.File, d.Line, d.Column, d.Severity, msg, d.Rule)
+}
+
+//Source is a manifest to lint along with the name it is reported under
+type Source struct {
+	Name string
+	Data []byte
+}
+
+//LintFiles lints the manifests in the files at paths, see Lint
+func LintFiles(paths ...string) ([]Diagnostic, error) {
+	sources := []Source{}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, Source{Name: path, Data: data})
+	}
+	return Lint(sources...), nil
+}
+
+//Lint checks the Workflows in manifests without a cluster. Each Workflow is checked against the schema of
+//the Workflow type and with Validate, and scripts taken from ConfigMaps and Secrets are looked up in the
+//other objects of the manifests. Diagnostics are sorted by file and line.
+func Lint(sources ...Source) []Diagnostic {
+	l := &linter{objects: map[string]map[string]bool{}}
+	for _, src := range sources {
+		l.lint(src)
+	}
+	for _, ref := range l.refs {
+		l.checkReference(ref)
+	}
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].File != l.diags[j].File {
+			return l.diags[i].File < l.diags[j].File
+		}
+		return l.diags[i].Line < l.diags[j].Line
+	})
+	return l.diags
+}
+
+//reference is a script a workflow takes from a ConfigMap or Secret
+type reference struct {
+	file      string
+	node      *yaml.Node
+	path      string
+	kind      string
+	namespace string
+	name      string
+	key       string
+}
+
+type linter struct {
+	diags []Diagnostic
+	//objects holds the keys of the ConfigMaps and Secrets in the manifests by kind, namespace and name
+	objects map[string]map[string]bool
+	refs    []reference
+}
+
+func (l *linter) report(file string, node *yaml.Node, severity string, rule string, path string, msg string) {
+	d := Diagnostic{File: file, Severity: severity, Rule: rule, Field: path, Message: msg}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	l.diags = append(l.diags, d)
+}
+
+//lineError finds the line in the errors of the YAML parser
+var lineError = regexp.MustCompile(`line (\d+)`)
+
+func (l *linter) lint(src Source) {
+	decoder := yaml.NewDecoder(bytes.NewReader(src.Data))
+	workflows := 0
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			d := Diagnostic{File: src.Name, Severity: SeverityError, Rule: RuleSyntax, Message: err.Error()}
+			if m := lineError.FindStringSubmatch(err.Error()); m != nil {
+				d.Line, _ = strconv.Atoi(m[1])
+				d.Column = 1
+			}
+			l.diags = append(l.diags, d)
+			return
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		root := doc.Content[0]
+		var meta struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+			Metadata   struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+			Data       map[string]interface{} `yaml:"data"`
+			StringData map[string]interface{} `yaml:"stringData"`
+			BinaryData map[string]interface{} `yaml:"binaryData"`
+		}
+		//objects that are not Kubernetes objects are not linted
+		if root.Decode(&meta) != nil {
+			continue
+		}
+
+		switch meta.Kind {
+		case "ConfigMap", "Secret":
+			keys := map[string]bool{}
+			for _, data := range []map[string]interface{}{meta.Data, meta.StringData, meta.BinaryData} {
+				for key := range data {
+					keys[key] = true
+				}
+			}
+			l.objects[objectKey(meta.Kind, meta.Metadata.Namespace, meta.Metadata.Name)] = keys
+		//the kind is also accepted as the Go type is named
+		case wfv1.Kind, "Workflow":
+			workflows++
+			l.lintWorkflow(src.Name, root)
+		}
+	}
+	if workflows == 0 {
+		l.report(src.Name, nil, SeverityWarning, RuleSchema, "", "no object of kind WorkFlow found")
+	}
+}
+
+func (l *linter) lintWorkflow(file string, root *yaml.Node) {
+	var schema reflect.Type
+	switch v := lookup(root, "apiVersion"); v.Value {
+	case wfv1.GroupVersion.String():
+		schema = reflect.TypeOf(wfv1.Workflow{})
+	case wfv2.GroupVersion.String():
+		schema = reflect.TypeOf(wfv2.Workflow{})
+	default:
+		l.report(file, v, SeverityError, RuleSchema, "apiVersion", fmt.Sprintf("got apiVersion %q, want %q or %q", v.Value, wfv1.GroupVersion.String(), wfv2.GroupVersion.String()))
+		return
+	}
+
+	before := len(l.diags)
+	l.checkSchema(file, root, schema, nil)
+
+	//unknown fields are dropped like by the API server, values of the wrong type fail to decode
+	var content interface{}
+	err := root.Decode(&content)
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(content)
+		if err == nil {
+			//v2 workflows are validated as v1, whose fields are found at the same paths
+			var wf wfv1.Workflow
+			if schema == reflect.TypeOf(wfv1.Workflow{}) {
+				err = json.Unmarshal(data, &wf)
+			} else {
+				var v2 wfv2.Workflow
+				err = json.Unmarshal(data, &v2)
+				if err == nil {
+					err = v2.ConvertTo(&wf)
+				}
+			}
+			if err == nil {
+				l.validate(file, root, &wf)
+				return
+			}
+		}
+	}
+	//values of the wrong type were reported by the schema check already
+	if len(l.diags) == before {
+		l.report(file, root, SeverityError, RuleSchema, "", fmt.Sprintf("failed to decode workflow: %v", err))
+	}
+}
+
+//validate reports the errors Validate finds in wf at the fields they were found in, and collects the
+//scripts wf takes from ConfigMaps and Secrets
+func (l *linter) validate(file string, root *yaml.Node, wf *wfv1.Workflow) {
+	for _, err := range Validate(wf) {
+		rule := RuleInvalid
+		switch err.Type {
+		case field.ErrorTypeRequired:
+			rule = RuleRequired
+		case field.ErrorTypeDuplicate:
+			rule = RuleDuplicate
+		}
+		l.report(file, lookup(root, err.Field), SeverityError, rule, err.Field, err.ErrorBody())
+	}
+
+	namespace := wf.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	for i, task := range wf.Spec.Tasks {
+		src := task.Command.ScriptFrom
+		if src == nil {
+			continue
+		}
+		path := field.NewPath("spec", "tasks").Index(i).Child("command", "scriptFrom")
+		switch {
+		case src.ConfigMapKeyRef != nil && src.SecretKeyRef == nil:
+			path = path.Child("configMapKeyRef")
+			l.refs = append(l.refs, reference{file, lookup(root, path.String()), path.String(), "ConfigMap", namespace, src.ConfigMapKeyRef.Name, src.ConfigMapKeyRef.Key})
+		case src.SecretKeyRef != nil && src.ConfigMapKeyRef == nil:
+			path = path.Child("secretKeyRef")
+			l.refs = append(l.refs, reference{file, lookup(root, path.String()), path.String(), "Secret", namespace, src.SecretKeyRef.Name, src.SecretKeyRef.Key})
+		}
+	}
+}
+
+//checkReference reports a script taken from a ConfigMap or Secret that lacks the key of the script, or
+//that is not in the manifests and has to exist in the cluster
+func (l *linter) checkReference(ref reference) {
+	if ref.name == "" || ref.key == "" {
+		return
+	}
+	keys, ok := l.objects[objectKey(ref.kind, ref.namespace, ref.name)]
+	if !ok {
+		//objects without a namespace are applied to the namespace of the workflow
+		keys, ok = l.objects[objectKey(ref.kind, "", ref.name)]
+	}
+	switch {
+	case !ok:
+		l.report(ref.file, ref.node, SeverityNote, RuleReference, ref.path, fmt.Sprintf("%s %s is not in the linted manifests and has to exist in namespace %s", ref.kind, ref.name, ref.namespace))
+	case !keys[ref.key]:
+		l.report(ref.file, ref.node, SeverityError, RuleReference, ref.path, fmt.Sprintf("%s %s has no key %s", ref.kind, ref.name, ref.key))
+	}
+}
+
+func objectKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+//checkSchema reports the fields of node that type t has no field for and values that cannot be decoded
+//into t, as the API server would reject them
+func (l *linter) checkSchema(file string, node *yaml.Node, t reflect.Type, path *field.Path) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+	//types such as times, durations and quantities decode themselves
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	wrongType := func(want string) {
+		l.report(file, node, SeverityError, RuleSchema, path.String(), "must be "+want)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			wrongType("an object")
+			return
+		}
+		fields := jsonFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			f, ok := fields[key.Value]
+			if !ok {
+				l.report(file, key, SeverityError, RuleSchema, path.Child(key.Value).String(), fmt.Sprintf("unknown field %q", key.Value))
+				continue
+			}
+			l.checkSchema(file, value, f.Type, child(path, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			wrongType("an object")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			l.checkSchema(file, node.Content[i+1], t.Elem(), path.Key(node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if node.Kind != yaml.ScalarNode {
+				wrongType("a string")
+			}
+			return
+		}
+		if node.Kind != yaml.SequenceNode {
+			wrongType("a list")
+			return
+		}
+		for i, item := range node.Content {
+			l.checkSchema(file, item, t.Elem(), path.Index(i))
+		}
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+			wrongType("a string")
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			wrongType("a boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			wrongType("an integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			wrongType("a number")
+		}
+	}
+}
+
+func child(path *field.Path, name string) *field.Path {
+	if path == nil {
+		return field.NewPath(name)
+	}
+	return path.Child(name)
+}
+
+//jsonFields returns the fields of struct type t by their JSON names, including the fields of inlined structs
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		switch {
+		case name == "-":
+		case name == "" && (f.Anonymous || tag == ",inline"):
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			for n, inner := range jsonFields(ft) {
+				fields[n] = inner
+			}
+		case name == "":
+			if f.PkgPath == "" {
+				fields[f.Name] = f
+			}
+		default:
+			fields[name] = f
+		}
+	}
+	return fields
+}
+
+//pathElement matches the names and indexes of a field path such as spec.tasks[1].command
+var pathElement = regexp.MustCompile(`[^.\[\]]+|\[[^\]]*\]`)
+
+//lookup returns the node of the field at path, or the closest node on the way to it when the field is not set
+func lookup(root *yaml.Node, path string) *yaml.Node {
+	node := root
+	for _, element := range pathElement.FindAllString(path, -1) {
+		var next *yaml.Node
+		switch {
+		case node.Kind == yaml.MappingNode:
+			name := element
+			if element[0] == '[' {
+				name = element[1 : len(element)-1]
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == name {
+					next = node.Content[i+1]
+				}
+			}
+		case node.Kind == yaml.SequenceNode && element[0] == '[':
+			i, err := strconv.Atoi(element[1 : len(element)-1])
+			if err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,126 @@
+package sdk
+
+import (
+	"testing"
+)
+
+const lintManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scripts
+data:
+  build.sh: echo build
+---
+apiVersion: trinity.cloudlego.com/v1
+kind: WorkFlow
+metadata:
+  name: wf1
+spec:
+  schedule: "99 * * * *"
+  storeartifact: true
+  tasks:
+  - name: build
+    command:
+      inline:
+        command: make
+      script: make
+  - name: build
+    command:
+      scriptFrom:
+        configMapKeyRef:
+          name: scripts
+          key: test.sh
+  - name: deploy
+    command:
+      scriptFrom:
+        secretKeyRef:
+          name: deploy
+          key: deploy.sh
+`
+
+func TestLint(t *testing.T) {
+	diags := Lint(Source{Name: "wf.yaml", Data: []byte(lintManifest)})
+
+	want := []struct {
+		line     int
+		severity string
+		rule     string
+		field    string
+	}{
+		{13, SeverityError, RuleInvalid, "spec.schedule"},
+		{14, SeverityError, RuleSchema, "spec.storeartifact"},
+		{18, SeverityError, RuleInvalid, "spec.tasks[0].command"},
+		{21, SeverityError, RuleDuplicate, "spec.tasks[1].name"},
+		{25, SeverityError, RuleReference, "spec.tasks[1].command.scriptFrom.configMapKeyRef"},
+		{31, SeverityNote, RuleReference, "spec.tasks[2].command.scriptFrom.secretKeyRef"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got diagnostics %v, want %d", diags, len(want))
+	}
+	for i, w := range want {
+		d := diags[i]
+		if d.File != "wf.yaml" || d.Line != w.line || d.Severity != w.severity || d.Rule != w.rule || d.Field != w.field {
+			t.Errorf("got diagnostic %+v, want %s %s for %s at line %d", d, w.severity, w.rule, w.field, w.line)
+		}
+	}
+}
+
+func TestLintValidManifest(t *testing.T) {
+	manifest := `apiVersion: trinity.cloudlego.com/v1
+kind: WorkFlow
+metadata:
+  name: wf1
+spec:
+  schedule: "*/5 * * * *"
+  outputLimit: 512
+  tasks:
+  - name: hello
+    command:
+      inline:
+        command: echo
+        args: ["hello"]
+    resources:
+      limits:
+        memory: 64Mi
+`
+	diags := Lint(Source{Name: "wf.yaml", Data: []byte(manifest)})
+	if len(diags) != 0 {
+		t.Errorf("got diagnostics %v, want none", diags)
+	}
+}
+
+func TestLintV2Manifest(t *testing.T) {
+	manifest := `apiVersion: trinity.cloudlego.com/v2
+kind: WorkFlow
+metadata:
+  name: wf1
+spec:
+  storeArtifacts: true
+  storeartifacts: true
+  tasks:
+  - name: hello
+    command:
+      inline:
+        command: echo
+      script: echo
+`
+	diags := Lint(Source{Name: "wf.yaml", Data: []byte(manifest)})
+
+	want := []struct {
+		line  int
+		rule  string
+		field string
+	}{
+		{7, RuleSchema, "spec.storeartifacts"},
+		{11, RuleInvalid, "spec.tasks[0].command"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got diagnostics %v, want %d", diags, len(want))
+	}
+	for i, w := range want {
+		d := diags[i]
+		if d.Line != w.line || d.Rule != w.rule || d.Field != w.field {
+			t.Errorf("got diagnostic %+v, want %s for %s at line %d", d, w.rule, w.field, w.line)
+		}
+	}
+}
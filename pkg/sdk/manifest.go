@@ -0,0 +1,79 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	wfv2 "github.com/arunprasadmudaliar/trinity/api/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+//ReadWorkflow reads a Workflow manifest, as applied with kubectl, from a YAML or JSON file
+func ReadWorkflow(path string) (*wfv1.Workflow, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := ParseWorkflow(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return wf, nil
+}
+
+//ParseWorkflow decodes a Workflow manifest of version v1 or v2 and returns the workflow as v1. The manifest
+//may hold other objects as well, e.g. the ConfigMap of a script, as long as it holds a single Workflow.
+//Fields unknown to the Workflow type are rejected, so misspelled fields are not silently dropped.
+func ParseWorkflow(data []byte) (*wfv1.Workflow, error) {
+	var wf *wfv1.Workflow
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %v", err)
+		}
+
+		var meta metav1.TypeMeta
+		err = yaml.Unmarshal(doc, &meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %v", err)
+		}
+		//the kind is also accepted as the Go type is named
+		if meta.Kind != wfv1.Kind && meta.Kind != "Workflow" {
+			continue
+		}
+		if wf != nil {
+			return nil, fmt.Errorf("manifest holds more than one workflow")
+		}
+
+		wf = &wfv1.Workflow{}
+		switch meta.APIVersion {
+		case wfv1.GroupVersion.String():
+			err = yaml.UnmarshalStrict(doc, wf)
+		case wfv2.GroupVersion.String():
+			v2 := &wfv2.Workflow{}
+			err = yaml.UnmarshalStrict(doc, v2)
+			if err == nil {
+				err = v2.ConvertTo(wf)
+			}
+		default:
+			return nil, fmt.Errorf("got apiVersion %q, want %q or %q", meta.APIVersion, wfv1.GroupVersion.String(), wfv2.GroupVersion.String())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse workflow: %v", err)
+		}
+	}
+	if wf == nil {
+		return nil, fmt.Errorf("manifest holds no object of kind WorkFlow")
+	}
+	return wf, nil
+}
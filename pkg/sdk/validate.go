@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"regexp"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+//schedulePattern and taskNamePattern are the patterns deployments/crd.yaml validates against
+var (
+	schedulePattern = regexp.MustCompile(`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`)
+	taskNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
+)
+
+//Validate checks a workflow against the rules of the CRD and the requirements of the runner, so
+//mistakes are reported before the workflow is submitted
+func Validate(wf *wfv1.Workflow) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(wf.Name) {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), wf.Name, msg))
+	}
+	return append(errs, ValidateSpec(&wf.Spec, field.NewPath("spec"))...)
+}
+
+//ValidateSpec checks the spec of a workflow found at path
+func ValidateSpec(spec *wfv1.WorkflowSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.Schedule != "" {
+		if !schedulePattern.MatchString(spec.Schedule) {
+			errs = append(errs, field.Invalid(path.Child("schedule"), spec.Schedule, "must be a cron schedule of five fields made of numbers, * and steps"))
+		} else if _, err := cron.ParseStandard(spec.Schedule); err != nil {
+			//the pattern of the CRD accepts values out of range, e.g. minute 99, which the CronJob never fires on
+			errs = append(errs, field.Invalid(path.Child("schedule"), spec.Schedule, err.Error()))
+		}
+	}
+	limits := []struct {
+		name  string
+		value *int32
+	}{
+		{"outputLimit", spec.OutputLimit},
+		{"successfulRunsHistoryLimit", spec.SuccessfulRunsHistoryLimit},
+		{"failedRunsHistoryLimit", spec.FailedRunsHistoryLimit},
+	}
+	for _, limit := range limits {
+		if limit.value != nil && *limit.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(limit.name), *limit.value, "must not be negative"))
+		}
+	}
+	if spec.RunTTL != nil && spec.RunTTL.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("runTTL"), spec.RunTTL.Duration.String(), "must not be negative"))
+	}
+	errs = append(errs, validateEnv(spec.Env, spec.SecretMounts, path)...)
+
+	if len(spec.Tasks) == 0 {
+		errs = append(errs, field.Required(path.Child("tasks"), "a workflow needs at least one task"))
+	}
+	names := map[string]bool{}
+	for i := range spec.Tasks {
+		task := &spec.Tasks[i]
+		taskPath := path.Child("tasks").Index(i)
+
+		switch {
+		case task.Name == "":
+			errs = append(errs, field.Required(taskPath.Child("name"), ""))
+		case !taskNamePattern.MatchString(task.Name):
+			errs = append(errs, field.Invalid(taskPath.Child("name"), task.Name, "may only contain letters and digits"))
+		case names[task.Name]:
+			errs = append(errs, field.Duplicate(taskPath.Child("name"), task.Name))
+		}
+		names[task.Name] = true
+
+		errs = append(errs, validateCommand(task, taskPath.Child("command"))...)
+		errs = append(errs, validateEnv(task.Env, task.SecretMounts, taskPath)...)
+	}
+	return errs
+}
+
+//validateCommand checks that a task runs exactly one of an inline command, a script or a script from a
+//ConfigMap or Secret
+func validateCommand(task *wfv1.Workflowtask, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	set := 0
+	if task.Command.Inline.Command != "" {
+		set++
+	}
+	if task.Command.Script != "" {
+		set++
+	}
+	if src := task.Command.ScriptFrom; src != nil {
+		set++
+		srcPath := path.Child("scriptFrom")
+		switch {
+		case src.ConfigMapKeyRef != nil && src.SecretKeyRef != nil:
+			errs = append(errs, field.Invalid(srcPath, "", "must set only one of configMapKeyRef and secretKeyRef"))
+		case src.ConfigMapKeyRef != nil:
+			if src.ConfigMapKeyRef.Name == "" {
+				errs = append(errs, field.Required(srcPath.Child("configMapKeyRef", "name"), ""))
+			}
+			if src.ConfigMapKeyRef.Key == "" {
+				errs = append(errs, field.Required(srcPath.Child("configMapKeyRef", "key"), ""))
+			}
+		case src.SecretKeyRef != nil:
+			if src.SecretKeyRef.Name == "" {
+				errs = append(errs, field.Required(srcPath.Child("secretKeyRef", "name"), ""))
+			}
+			if src.SecretKeyRef.Key == "" {
+				errs = append(errs, field.Required(srcPath.Child("secretKeyRef", "key"), ""))
+			}
+		default:
+			errs = append(errs, field.Required(srcPath, "must set one of configMapKeyRef and secretKeyRef"))
+		}
+	}
+
+	switch {
+	case set == 0:
+		errs = append(errs, field.Required(path, "must set one of inline.command, script and scriptFrom"))
+	case set > 1:
+		errs = append(errs, field.Invalid(path, "", "must set only one of inline.command, script and scriptFrom"))
+	}
+	return errs
+}
+
+//validateEnv checks the environment variables and secret mounts of a workflow or task
+func validateEnv(env []corev1.EnvVar, mounts []wfv1.SecretMount, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, e := range env {
+		if e.Name == "" {
+			errs = append(errs, field.Required(path.Child("env").Index(i).Child("name"), ""))
+		}
+	}
+	for i, m := range mounts {
+		mountPath := path.Child("secretMounts").Index(i)
+		if m.SecretName == "" {
+			errs = append(errs, field.Required(mountPath.Child("secretName"), ""))
+		}
+		if m.MountPath == "" {
+			errs = append(errs, field.Required(mountPath.Child("mountPath"), ""))
+		}
+	}
+	return errs
+}
@@ -1,31 +1,40 @@
 package utils
 
 import (
+	"encoding/base64"
+	"strconv"
+
 	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	batch "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func cronJobSpec(name string, namespace string, schedule string) *batch.CronJob {
+// RunnerServiceAccount is the service account, role and role binding the controller maintains in every namespace with workflows
+const RunnerServiceAccount = "trinity-runner"
+
+func cronJobSpec(name string, namespace string, spec *wfv1.WorkflowSpec) *batch.CronJob {
 	var zero *int32
 	zero = new(int32)
 	*zero = 0
-	return &batch.CronJob{
+	cron := &batch.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "wf-cron-" + name,
 			Namespace: namespace,
 		},
 		Spec: batch.CronJobSpec{
-			Schedule:                   schedule,
+			Schedule:                   spec.Schedule,
 			FailedJobsHistoryLimit:     zero,
 			SuccessfulJobsHistoryLimit: zero,
 			JobTemplate: batch.JobTemplateSpec{
 				Spec: batchv1.JobSpec{
 					Template: v1.PodTemplateSpec{
 						Spec: v1.PodSpec{
+							ServiceAccountName: serviceAccountName(spec),
 							Containers: []v1.Container{
 								{
 									Name:            name,
@@ -46,6 +55,8 @@ func cronJobSpec(name string, namespace string, schedule string) *batch.CronJob
 			},
 		},
 	}
+	applyPodOptions(&cron.Spec.JobTemplate.Spec.Template.Spec, spec.PodDefaults)
+	return cron
 }
 
 func podSpec(name string, namespace string, image string) *v1.Pod {
@@ -70,26 +81,49 @@ func podSpec(name string, namespace string, image string) *v1.Pod {
 	}
 }
 
-func jobSpec(name string, namespace string, image string, runid string, taskid string, creds wfv1.MinioCreds) *batchv1.Job {
+// jobSpec returns the job running a task of the run named run. env is set by the runner and takes precedence over the workflow and task env.
+func jobSpec(name string, namespace string, image string, run string, taskid string, last bool, creds wfv1.MinioCreds, spec *wfv1.WorkflowSpec, task *wfv1.Workflowtask, env []v1.EnvVar) *batchv1.Job {
 	var ttl *int32
 	ttl = new(int32)
 	*ttl = 0
-	return &batchv1.Job{
+	//task pods report results through the termination message and need no API access
+	automount := false
+	args := []string{
+		"exec",
+		"-w", name,
+		"-n", namespace,
+		"-r", run,
+		"-t", taskid,
+	}
+	if last {
+		args = append(args, "--last")
+	}
+	labels := map[string]string{
+		"workflow": name,
+		"run":      run,
+	}
+	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name + "-task-" + taskid,
+			Name:      run + "-task-" + taskid,
 			Namespace: namespace,
+			Labels:    labels,
 		},
 		Spec: batchv1.JobSpec{
 			TTLSecondsAfterFinished: ttl,
 			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
 				Spec: v1.PodSpec{
+					ServiceAccountName:           serviceAccountName(spec),
+					AutomountServiceAccountToken: &automount,
 					Containers: []v1.Container{
 						{
 							Name:            name,
 							Image:           image,
 							ImagePullPolicy: "Always",
 							Command:         []string{"trinity"},
-							Env: []v1.EnvVar{
+							Env: MergeEnv(spec.Env, task.Env, []v1.EnvVar{
 								{
 									Name:  "MINIO_ROOT_USER",
 									Value: creds.AccessKey,
@@ -98,26 +132,84 @@ func jobSpec(name string, namespace string, image string, runid string, taskid s
 									Name:  "MINIO_ROOT_PASSWORD",
 									Value: creds.SecretKey,
 								},
-							},
-							Args: []string{
-								"exec",
-								"-w", name,
-								"-n", namespace,
-								"-r", runid,
-								"-t", taskid,
-							},
+							}, env),
+							EnvFrom:      append(append([]v1.EnvFromSource{}, spec.EnvFrom...), task.EnvFrom...),
+							VolumeMounts: secretVolumeMounts(spec.SecretMounts, task.SecretMounts),
+							Args:         args,
 						},
 					},
+					Volumes:       secretVolumes(spec.SecretMounts, task.SecretMounts),
 					RestartPolicy: "Never",
 				},
 			},
 		},
 	}
+	applyPodOptions(&job.Spec.Template.Spec, mergePodOptions(spec.PodDefaults, &task.PodOptions))
+	return job
+}
 
+// MergeEnv merges lists of environment variables. A variable in a later list replaces one with the same name in an earlier list.
+func MergeEnv(lists ...[]v1.EnvVar) []v1.EnvVar {
+	env := []v1.EnvVar{}
+	index := map[string]int{}
+	for _, list := range lists {
+		for _, e := range list {
+			if i, ok := index[e.Name]; ok {
+				env[i] = e
+				continue
+			}
+			index[e.Name] = len(env)
+			env = append(env, e)
+		}
+	}
+	return env
 }
 
-func minioPodSpec(name string, namespace string, creds wfv1.MinioCreds) *v1.Pod {
-	return &v1.Pod{
+// mergeSecretMounts merges workflow and task secret mounts. A task mount replaces a workflow mount with the same mount path.
+func mergeSecretMounts(defaults []wfv1.SecretMount, overrides []wfv1.SecretMount) []wfv1.SecretMount {
+	mounts := []wfv1.SecretMount{}
+	index := map[string]int{}
+	for _, m := range append(append([]wfv1.SecretMount{}, defaults...), overrides...) {
+		if i, ok := index[m.MountPath]; ok {
+			mounts[i] = m
+			continue
+		}
+		index[m.MountPath] = len(mounts)
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+func secretVolumes(defaults []wfv1.SecretMount, overrides []wfv1.SecretMount) []v1.Volume {
+	volumes := []v1.Volume{}
+	for i, m := range mergeSecretMounts(defaults, overrides) {
+		volumes = append(volumes, v1.Volume{
+			Name: "secret-" + strconv.Itoa(i),
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: m.SecretName,
+					Items:      m.Items,
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+func secretVolumeMounts(defaults []wfv1.SecretMount, overrides []wfv1.SecretMount) []v1.VolumeMount {
+	mounts := []v1.VolumeMount{}
+	for i, m := range mergeSecretMounts(defaults, overrides) {
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      "secret-" + strconv.Itoa(i),
+			MountPath: m.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
+
+func minioPodSpec(name string, namespace string, creds wfv1.MinioCreds, defaults *wfv1.PodOptions) *v1.Pod {
+	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name + "-artifact",
 			Namespace: namespace,
@@ -149,6 +241,99 @@ func minioPodSpec(name string, namespace string, creds wfv1.MinioCreds) *v1.Pod
 			RestartPolicy: "Never",
 		},
 	}
+	applyPodOptions(&pod.Spec, defaults)
+	return pod
+}
+
+func runnerServiceAccountSpec(namespace string) *v1.ServiceAccount {
+	return &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RunnerServiceAccount,
+			Namespace: namespace,
+		},
+	}
+}
+
+// runnerRoleSpec grants only what the runner and the executor need within the namespace
+func runnerRoleSpec(namespace string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RunnerServiceAccount,
+			Namespace: namespace,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"trinity.cloudlego.com"},
+				Resources: []string{"workflows"},
+				Verbs:     []string{"get"},
+			},
+			{
+				//the runner records runs on the status only and cannot change the spec of a workflow
+				APIGroups: []string{"trinity.cloudlego.com"},
+				Resources: []string{"workflows/status"},
+				Verbs:     []string{"update", "patch"},
+			},
+			{
+				APIGroups: []string{"trinity.cloudlego.com"},
+				Resources: []string{"workflowruns", "workflowruns/status"},
+				Verbs:     []string{"get", "list", "create", "update", "patch"},
+			},
+			{
+				APIGroups: []string{"batch"},
+				Resources: []string{"jobs"},
+				Verbs:     []string{"get", "list", "watch", "create", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list", "watch", "create", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods/log"},
+				Verbs:     []string{"get"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"services"},
+				Verbs:     []string{"get", "create", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps", "secrets"},
+				Verbs:     []string{"get"},
+			},
+		},
+	}
+}
+
+func runnerRoleBindingSpec(namespace string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RunnerServiceAccount,
+			Namespace: namespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     RunnerServiceAccount,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      RunnerServiceAccount,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+// serviceAccountName returns the service account the runner and task pods of a workflow run under
+func serviceAccountName(spec *wfv1.WorkflowSpec) string {
+	if spec.ServiceAccountName != "" {
+		return spec.ServiceAccountName
+	}
+	return RunnerServiceAccount
 }
 
 func minioSvcSpec(name string, namespace string) *v1.Service {
@@ -172,3 +357,157 @@ func minioSvcSpec(name string, namespace string) *v1.Service {
 		},
 	}
 }
+
+// mergePodOptions returns defaults with every field set in overrides replaced. Node selectors are merged and tolerations are combined.
+func mergePodOptions(defaults *wfv1.PodOptions, overrides *wfv1.PodOptions) *wfv1.PodOptions {
+	merged := &wfv1.PodOptions{}
+	if defaults != nil {
+		merged = defaults.DeepCopy()
+	}
+	if overrides == nil {
+		return merged
+	}
+	o := overrides.DeepCopy()
+	if o.Resources != nil {
+		merged.Resources = o.Resources
+	}
+	if len(o.NodeSelector) > 0 {
+		if merged.NodeSelector == nil {
+			merged.NodeSelector = map[string]string{}
+		}
+		for k, v := range o.NodeSelector {
+			merged.NodeSelector[k] = v
+		}
+	}
+	merged.Tolerations = append(merged.Tolerations, o.Tolerations...)
+	if o.Affinity != nil {
+		merged.Affinity = o.Affinity
+	}
+	if o.PriorityClassName != "" {
+		merged.PriorityClassName = o.PriorityClassName
+	}
+	if o.SecurityContext != nil {
+		merged.SecurityContext = o.SecurityContext
+	}
+	if o.ContainerSecurityContext != nil {
+		merged.ContainerSecurityContext = o.ContainerSecurityContext
+	}
+	return merged
+}
+
+// applyPodOptions sets resources and scheduling constraints on a pod spec and all of its containers
+func applyPodOptions(pod *v1.PodSpec, opts *wfv1.PodOptions) {
+	if opts == nil {
+		return
+	}
+	pod.NodeSelector = opts.NodeSelector
+	pod.Tolerations = opts.Tolerations
+	pod.Affinity = opts.Affinity
+	pod.PriorityClassName = opts.PriorityClassName
+	pod.SecurityContext = opts.SecurityContext
+	for i := range pod.Containers {
+		if opts.Resources != nil {
+			pod.Containers[i].Resources = *opts.Resources
+		}
+		pod.Containers[i].SecurityContext = opts.ContainerSecurityContext
+	}
+}
+
+// WebhookConfiguration is the name of the validating and mutating webhook configurations of the controller
+const WebhookConfiguration = "trinity-webhook"
+
+// WebhookService locates the webhook server of the controller behind a Service
+type WebhookService struct {
+	Name      string
+	Namespace string
+	Port      int32
+}
+
+// webhookRules sends the creation and updates of workflows to a webhook
+func webhookRules() []admissionregistrationv1.RuleWithOperations {
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{wfv1.GroupVersion.Group},
+				APIVersions: []string{wfv1.GroupVersion.Version},
+				Resources:   []string{"workflows"},
+			},
+		},
+	}
+}
+
+func webhookClientConfig(svc WebhookService, path string, caBundle []byte) admissionregistrationv1.WebhookClientConfig {
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+			Path:      &path,
+			Port:      &svc.Port,
+		},
+		CABundle: caBundle,
+	}
+}
+
+func validatingWebhookSpec(svc WebhookService, caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: WebhookConfiguration,
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:                    "validate.workflows.trinity.cloudlego.com",
+				ClientConfig:            webhookClientConfig(svc, "/validate", caBundle),
+				Rules:                   webhookRules(),
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+}
+
+func mutatingWebhookSpec(svc WebhookService, caBundle []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: WebhookConfiguration,
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{
+				Name:                    "default.workflows.trinity.cloudlego.com",
+				ClientConfig:            webhookClientConfig(svc, "/mutate", caBundle),
+				Rules:                   webhookRules(),
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+}
+
+// WorkflowCRD is the name of the CustomResourceDefinition of workflows, whose versions are converted by the
+// conversion webhook of the controller
+const WorkflowCRD = "workflows.trinity.cloudlego.com"
+
+// crdConversionSpec is the spec.conversion of the workflow CRD, sending conversions to svc
+func crdConversionSpec(svc WebhookService, caBundle []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"name":      svc.Name,
+					"namespace": svc.Namespace,
+					"path":      "/convert",
+					"port":      int64(svc.Port),
+				},
+				"caBundle": base64.StdEncoding.EncodeToString(caBundle),
+			},
+			"conversionReviewVersions": []interface{}{"v1"},
+		},
+	}
+}
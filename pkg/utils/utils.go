@@ -1,11 +1,16 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io/ioutil"
-	"log"
 	"math/rand"
 	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"syscall"
 	"time"
 
 	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
@@ -13,36 +18,63 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
+	batch "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-//Client returns a kubernetes client
-func Client(configpath string) (*kubernetes.Clientset, error) {
-
+//Config returns the configuration for the cluster described by the kubeconfig file at configpath, or
+//the in-cluster configuration when configpath is empty
+func Config(configpath string) (*rest.Config, error) {
 	if configpath == "" {
-		logrus.Info("Using Incluster configuration")
 		config, err := rest.InClusterConfig()
 		if err != nil {
-			logrus.Fatalf("Error occured while reading incluster kubeconfig:%v", err)
-			return nil, err
+			return nil, fmt.Errorf("failed to read incluster configuration: %v", err)
 		}
-		return kubernetes.NewForConfig(config)
+		return config, nil
 	}
 
-	logrus.Infof("Using configuration file:%s", configpath)
 	config, err := clientcmd.BuildConfigFromFlags("", configpath)
 	if err != nil {
-		logrus.Fatalf("Error occured while reading kubeconfig:%v", err)
+		return nil, fmt.Errorf("failed to read kubeconfig %s: %v", configpath, err)
+	}
+	return config, nil
+}
+
+//Client returns a kubernetes client
+func Client(configpath string) (*kubernetes.Clientset, error) {
+	config, err := Config(configpath)
+	if err != nil {
 		return nil, err
 	}
 	return kubernetes.NewForConfig(config)
 }
 
+//SignalContext returns a context that is cancelled when the process receives SIGINT or SIGTERM, so
+//commands can stop what they are doing before kubernetes kills them
+func SignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(ch)
+	}()
+	return ctx, cancel
+}
+
 func GetObjectMetaData(obj interface{}) (objectMeta metav1.ObjectMeta) {
 	switch object := obj.(type) {
 	case *v1.Namespace:
@@ -52,19 +84,23 @@ func GetObjectMetaData(obj interface{}) (objectMeta metav1.ObjectMeta) {
 	return objectMeta
 }
 
-func getCron(kc *kubernetes.Clientset, name string, namespace string) bool {
-	_, err := kc.BatchV1beta1().CronJobs(namespace).Get(context.Background(), "wf-cron-"+name, metav1.GetOptions{})
+func getCron(ctx context.Context, kc kubernetes.Interface, name string, namespace string) bool {
+	_, err := kc.BatchV1beta1().CronJobs(namespace).Get(ctx, "wf-cron-"+name, metav1.GetOptions{})
 	if err != nil {
 		return false
 	}
 	return true
 }
 
-func CreateCron(kc *kubernetes.Clientset, name string, namespace string, schedule string) (bool, error) {
-	jobexists := getCron(kc, name, namespace)
+func GetCron(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (*batch.CronJob, error) {
+	return kc.BatchV1beta1().CronJobs(namespace).Get(ctx, "wf-cron-"+name, metav1.GetOptions{})
+}
+
+func CreateCron(ctx context.Context, kc kubernetes.Interface, name string, namespace string, spec *wfv1.WorkflowSpec) (bool, error) {
+	jobexists := getCron(ctx, kc, name, namespace)
 
 	if !jobexists {
-		_, err := kc.BatchV1beta1().CronJobs(namespace).Create(context.Background(), cronJobSpec(name, namespace, schedule), metav1.CreateOptions{})
+		_, err := kc.BatchV1beta1().CronJobs(namespace).Create(ctx, cronJobSpec(name, namespace, spec), metav1.CreateOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -76,10 +112,10 @@ func CreateCron(kc *kubernetes.Clientset, name string, namespace string, schedul
 	return false, nil
 }
 
-func DeleteCron(kc *kubernetes.Clientset, name string, namespace string) (bool, error) {
-	jobexists := getCron(kc, name, namespace)
+func DeleteCron(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (bool, error) {
+	jobexists := getCron(ctx, kc, name, namespace)
 	if jobexists {
-		err := kc.BatchV1beta1().CronJobs(namespace).Delete(context.Background(), "wf-cron-"+name, metav1.DeleteOptions{})
+		err := kc.BatchV1beta1().CronJobs(namespace).Delete(ctx, "wf-cron-"+name, metav1.DeleteOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -90,8 +126,8 @@ func DeleteCron(kc *kubernetes.Clientset, name string, namespace string) (bool,
 	return false, nil
 }
 
-func UpdateCron(kc *kubernetes.Clientset, name string, namespace string, schedule string) error {
-	_, err := kc.BatchV1beta1().CronJobs(namespace).Update(context.Background(), cronJobSpec(name, namespace, schedule), metav1.UpdateOptions{})
+func UpdateCron(ctx context.Context, kc kubernetes.Interface, name string, namespace string, spec *wfv1.WorkflowSpec) error {
+	_, err := kc.BatchV1beta1().CronJobs(namespace).Update(ctx, cronJobSpec(name, namespace, spec), metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
@@ -99,17 +135,113 @@ func UpdateCron(kc *kubernetes.Clientset, name string, namespace string, schedul
 	return nil
 }
 
-func CreatePod(kc *kubernetes.Clientset, name string, namespace string, image string) (*v1.Pod, error) {
+//EnsureRunnerRBAC creates or updates the service account, role and role binding used by runner and task pods in a namespace
+func EnsureRunnerRBAC(ctx context.Context, kc kubernetes.Interface, namespace string) error {
+	_, err := kc.CoreV1().ServiceAccounts(namespace).Get(ctx, RunnerServiceAccount, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = kc.CoreV1().ServiceAccounts(namespace).Create(ctx, runnerServiceAccountSpec(namespace), metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	role := runnerRoleSpec(namespace)
+	existingRole, err := kc.RbacV1().Roles(namespace).Get(ctx, role.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = kc.RbacV1().Roles(namespace).Create(ctx, role, metav1.CreateOptions{})
+	} else if err == nil && !reflect.DeepEqual(existingRole.Rules, role.Rules) {
+		existingRole.Rules = role.Rules
+		_, err = kc.RbacV1().Roles(namespace).Update(ctx, existingRole, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	binding := runnerRoleBindingSpec(namespace)
+	existingBinding, err := kc.RbacV1().RoleBindings(namespace).Get(ctx, binding.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = kc.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{})
+	} else if err == nil && !reflect.DeepEqual(existingBinding.Subjects, binding.Subjects) {
+		existingBinding.Subjects = binding.Subjects
+		_, err = kc.RbacV1().RoleBindings(namespace).Update(ctx, existingBinding, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+//EnsureWebhookConfigurations creates or updates the validating and mutating webhook configurations that send
+//workflows to the webhook server behind svc, which serves a certificate signed by caBundle
+func EnsureWebhookConfigurations(ctx context.Context, kc kubernetes.Interface, svc WebhookService, caBundle []byte) error {
+	validating := validatingWebhookSpec(svc, caBundle)
+	existingValidating, err := kc.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, validating.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = kc.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(ctx, validating, metav1.CreateOptions{})
+	} else if err == nil && !reflect.DeepEqual(existingValidating.Webhooks, validating.Webhooks) {
+		existingValidating.Webhooks = validating.Webhooks
+		_, err = kc.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(ctx, existingValidating, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	mutating := mutatingWebhookSpec(svc, caBundle)
+	existingMutating, err := kc.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, mutating.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = kc.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(ctx, mutating, metav1.CreateOptions{})
+	} else if err == nil && !reflect.DeepEqual(existingMutating.Webhooks, mutating.Webhooks) {
+		existingMutating.Webhooks = mutating.Webhooks
+		_, err = kc.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(ctx, existingMutating, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+//crdResource is the resource of CustomResourceDefinitions, which are read through the dynamic client since
+//the apiextensions clientset is not a dependency
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+//EnsureCRDConversion configures the workflow CRD to convert between versions through the conversion webhook
+//behind svc, trusting caBundle
+func EnsureCRDConversion(ctx context.Context, dc dynamic.Interface, svc WebhookService, caBundle []byte) error {
+	crd, err := dc.Resource(crdResource).Get(ctx, WorkflowCRD, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	conversion := crdConversionSpec(svc, caBundle)
+	existing, _, _ := unstructured.NestedMap(crd.Object, "spec", "conversion")
+	if reflect.DeepEqual(existing, conversion) {
+		return nil
+	}
+	err = unstructured.SetNestedMap(crd.Object, conversion, "spec", "conversion")
+	if err != nil {
+		return err
+	}
+	_, err = dc.Resource(crdResource).Update(ctx, crd, metav1.UpdateOptions{})
+	return err
+}
+
+//SaveSecret creates a secret, or replaces the data of the secret when it exists
+func SaveSecret(ctx context.Context, kc kubernetes.Interface, secret *v1.Secret) (*v1.Secret, error) {
+	existing, err := kc.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return kc.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing.Data = secret.Data
+	return kc.CoreV1().Secrets(secret.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+}
+
+func CreatePod(ctx context.Context, kc kubernetes.Interface, name string, namespace string, image string) (*v1.Pod, error) {
 	podspec := podSpec(name, namespace, image)
-	pod, err := kc.CoreV1().Pods(namespace).Create(context.Background(), podspec, metav1.CreateOptions{})
+	pod, err := kc.CoreV1().Pods(namespace).Create(ctx, podspec, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return pod, nil
 }
 
-func DeleteJobPod(kc *kubernetes.Clientset, name string, namespace string) error {
-	pods, err := kc.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+func DeleteJobPod(ctx context.Context, kc kubernetes.Interface, name string, namespace string) error {
+	pods, err := kc.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: "job-name=" + name,
 	})
 
@@ -118,7 +250,7 @@ func DeleteJobPod(kc *kubernetes.Clientset, name string, namespace string) error
 	}
 
 	for _, pod := range pods.Items {
-		err := kc.CoreV1().Pods(namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{})
+		err := kc.CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
 		if err != nil {
 			return err
 		}
@@ -126,56 +258,74 @@ func DeleteJobPod(kc *kubernetes.Clientset, name string, namespace string) error
 	return nil
 }
 
-func WatchPod(kc *kubernetes.Clientset, name string, namespace string) (watch.Interface, error) {
+func WatchPod(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (watch.Interface, error) {
 	opts := metav1.ListOptions{
 		FieldSelector: "metadata.name=" + name,
 	}
-	return kc.CoreV1().Pods(namespace).Watch(context.Background(), opts)
+	return kc.CoreV1().Pods(namespace).Watch(ctx, opts)
+}
+
+func GetConfigMap(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (*v1.ConfigMap, error) {
+	return kc.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
-func CreateJob(kc *kubernetes.Clientset, name string, namespace string, image string, runid string, taskid string, creds wfv1.MinioCreds) (*batchv1.Job, error) {
-	jobspec := jobSpec(name, namespace, image, runid, taskid, creds)
-	job, err := kc.BatchV1().Jobs(namespace).Create(context.Background(), jobspec, metav1.CreateOptions{})
+func GetSecret(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (*v1.Secret, error) {
+	return kc.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func CreateJob(ctx context.Context, kc kubernetes.Interface, name string, namespace string, image string, run string, taskid string, last bool, creds wfv1.MinioCreds, spec *wfv1.WorkflowSpec, task *wfv1.Workflowtask, env []v1.EnvVar) (*batchv1.Job, error) {
+	jobspec := jobSpec(name, namespace, image, run, taskid, last, creds, spec, task, env)
+	job, err := kc.BatchV1().Jobs(namespace).Create(ctx, jobspec, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return job, nil
 }
 
-func DeleteJob(kc *kubernetes.Clientset, name string, namespace string) error {
-	err := kc.BatchV1().Jobs(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+func DeleteJob(ctx context.Context, kc kubernetes.Interface, name string, namespace string) error {
+	err := kc.BatchV1().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	return err
 }
 
-func WatchJob(kc *kubernetes.Clientset, name string, namespace string) (watch.Interface, error) {
+//DeleteRunJobs removes the jobs, and with them the pods and logs, left behind by the run named run
+func DeleteRunJobs(ctx context.Context, kc kubernetes.Interface, name string, namespace string, run string) error {
+	background := metav1.DeletePropagationBackground
+	return kc.BatchV1().Jobs(namespace).DeleteCollection(ctx, metav1.DeleteOptions{
+		PropagationPolicy: &background,
+	}, metav1.ListOptions{
+		LabelSelector: "workflow=" + name + ",run=" + run,
+	})
+}
+
+func WatchJob(ctx context.Context, kc kubernetes.Interface, name string, namespace string) (watch.Interface, error) {
 	opts := metav1.ListOptions{
 		FieldSelector: "metadata.name=" + name,
 	}
 
-	return kc.BatchV1().Jobs(namespace).Watch(context.Background(), opts)
+	return kc.BatchV1().Jobs(namespace).Watch(ctx, opts)
 }
 
-func DeployMinio(kc *kubernetes.Clientset, name string, namespace string, creds wfv1.MinioCreds) (*v1.Pod, *v1.Service, error) {
-	podspec := minioPodSpec(name, namespace, creds)
+func DeployMinio(ctx context.Context, kc kubernetes.Interface, name string, namespace string, creds wfv1.MinioCreds, defaults *wfv1.PodOptions) (*v1.Pod, *v1.Service, error) {
+	podspec := minioPodSpec(name, namespace, creds, defaults)
 	svcspec := minioSvcSpec(name, namespace)
-	pod, err := kc.CoreV1().Pods(namespace).Create(context.Background(), podspec, metav1.CreateOptions{})
+	pod, err := kc.CoreV1().Pods(namespace).Create(ctx, podspec, metav1.CreateOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
-	svc, err := kc.CoreV1().Services(namespace).Create(context.Background(), svcspec, metav1.CreateOptions{})
+	svc, err := kc.CoreV1().Services(namespace).Create(ctx, svcspec, metav1.CreateOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
 	return pod, svc, nil
 }
 
-func DeleteMinio(kc *kubernetes.Clientset, pod *v1.Pod, svc *v1.Service) error {
-	err := kc.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(context.Background(), pod.ObjectMeta.Name, metav1.DeleteOptions{})
+func DeleteMinio(ctx context.Context, kc kubernetes.Interface, pod *v1.Pod, svc *v1.Service) error {
+	err := kc.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(ctx, pod.ObjectMeta.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = kc.CoreV1().Services(svc.ObjectMeta.Namespace).Delete(context.Background(), svc.ObjectMeta.Name, metav1.DeleteOptions{})
+	err = kc.CoreV1().Services(svc.ObjectMeta.Namespace).Delete(ctx, svc.ObjectMeta.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
@@ -193,9 +343,7 @@ func MinioCredential() string {
 	return string(b)
 }
 
-func UploadArtifacts(bucket string, url string, artifacts []string) error {
-
-	ctx := context.Background()
+func UploadArtifacts(ctx context.Context, bucket string, url string, artifacts []string) error {
 	endpoint := url
 	accessKeyID := os.Getenv("MINIO_ROOT_USER")
 	secretAccessKey := os.Getenv("MINIO_ROOT_PASSWORD")
@@ -218,16 +366,14 @@ func UploadArtifacts(bucket string, url string, artifacts []string) error {
 	for _, artifact := range artifacts {
 		_, err := mc.FPutObject(ctx, bucket, artifact, "/artifacts/outgoing/"+artifact, minio.PutObjectOptions{})
 		if err != nil {
-			logrus.WithError(err).Errorf("Failed to upload artifact %s", artifact)
-			return err
+			return fmt.Errorf("failed to upload artifact %s: %v", artifact, err)
 		}
-
 	}
 
 	return nil
 }
 
-func DownloadArtifacts(bucket string, url string) error {
+func DownloadArtifacts(ctx context.Context, bucket string, url string) error {
 	endpoint := url
 	accessKeyID := os.Getenv("MINIO_ROOT_USER")
 	secretAccessKey := os.Getenv("MINIO_ROOT_PASSWORD")
@@ -241,31 +387,103 @@ func DownloadArtifacts(bucket string, url string) error {
 	if err != nil {
 		return err
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	objectCh := mc.ListObjects(ctx, bucket, minio.ListObjectsOptions{})
 	for object := range objectCh {
 		if object.Err != nil {
-			//logrus.WithError(err).Error("failed to list artifacts in storage")
-			return err
+			return fmt.Errorf("failed to list artifacts in storage: %v", object.Err)
 		}
 
-		err = mc.FGetObject(context.Background(), bucket, object.Key, "/artifacts/incoming/"+object.Key, minio.GetObjectOptions{})
+		err = mc.FGetObject(ctx, bucket, object.Key, "/artifacts/incoming/"+object.Key, minio.GetObjectOptions{})
 		if err != nil {
-			logrus.WithError(err).Errorf("failed to download artifact %s", object.Key)
-			return err
+			return fmt.Errorf("failed to download artifact %s: %v", object.Key, err)
 		}
 	}
 	return nil
 }
 
-func ReadArtifactsFolder(dir string) []string {
+//UploadOutput stores a task output in the artifact store under key
+func UploadOutput(ctx context.Context, bucket string, url string, key string, output []byte) error {
+	mc, err := minioClient(url, os.Getenv("MINIO_ROOT_USER"), os.Getenv("MINIO_ROOT_PASSWORD"))
+	if err != nil {
+		return err
+	}
+
+	exists, err := mc.BucketExists(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = mc.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = mc.PutObject(ctx, bucket, key, bytes.NewReader(output), int64(len(output)), minio.PutObjectOptions{ContentType: "text/plain"})
+	return err
+}
+
+//DownloadOutput reads a task output stored by UploadOutput
+func DownloadOutput(ctx context.Context, bucket string, url string, key string, creds wfv1.MinioCreds) ([]byte, error) {
+	mc, err := minioClient(url, creds.AccessKey, creds.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := mc.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return ioutil.ReadAll(object)
+}
+
+func minioClient(url string, accessKeyID string, secretAccessKey string) (*minio.Client, error) {
+	return minio.New(url, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: false,
+	})
+}
+
+//Logger returns log, or the standard logger when log is nil. Everything in trinity that accepts a logger
+//goes through Logger, so callers pass nil to log to the standard logger.
+func Logger(log logrus.FieldLogger) logrus.FieldLogger {
+	if log == nil {
+		return logrus.StandardLogger()
+	}
+	return log
+}
+
+//GetPodLogs returns the log of the container of a pod
+func GetPodLogs(ctx context.Context, kc kubernetes.Interface, name string, namespace string) ([]byte, error) {
+	return kc.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{}).DoRaw(ctx)
+}
+
+//GetJobPods returns the pods created for a job, oldest first
+func GetJobPods(ctx context.Context, kc kubernetes.Interface, job string, namespace string) ([]v1.Pod, error) {
+	pods, err := kc.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "job-name=" + job,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+	return items, nil
+}
+
+//ReadArtifactsFolder lists the files in a folder under /artifacts
+func ReadArtifactsFolder(dir string) ([]string, error) {
 	artifacts := []string{}
 	files, err := ioutil.ReadDir("/artifacts/" + dir + "/")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	for _, f := range files {
@@ -273,10 +491,5 @@ func ReadArtifactsFolder(dir string) []string {
 			artifacts = append(artifacts, f.Name())
 		}
 	}
-	return artifacts
-}
-
-func Timestamp() string {
-	dt := time.Now()
-	return dt.Format("01-02-2006 15:04:05")
+	return artifacts, nil
 }
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//certValidity is how long generated certificates are valid. They are renewed by the first start of the
+//webhook server within renewBefore of their expiry.
+const (
+	certValidity = 365 * 24 * time.Hour
+	renewBefore  = 30 * 24 * time.Hour
+)
+
+//Certificates holds the PEM encoded serving certificate and key of the webhook server and the CA that signed
+//the certificate, which the API server is configured to trust
+type Certificates struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+}
+
+//dnsNames returns the names the API server uses to reach a service
+func dnsNames(svc utils.WebhookService) []string {
+	return []string{
+		svc.Name,
+		svc.Name + "." + svc.Namespace,
+		svc.Name + "." + svc.Namespace + ".svc",
+		svc.Name + "." + svc.Namespace + ".svc.cluster.local",
+	}
+}
+
+//GenerateCertificates creates a self-signed CA and a serving certificate for the names of svc signed by it
+func GenerateCertificates(svc utils.WebhookService) (*Certificates, error) {
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: "trinity-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	names := dnsNames(svc)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: names[2]},
+		DNSNames:     names,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create serving certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificates{
+		CA:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+//valid tells whether certs can serve svc for longer than renewBefore
+func (c *Certificates) valid(svc utils.WebhookService) bool {
+	pair, err := tls.X509KeyPair(c.Cert, c.Key)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(c.CA) {
+		return false
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     dnsNames(svc)[2],
+		Roots:       pool,
+		CurrentTime: time.Now().Add(renewBefore),
+	})
+	return err == nil
+}
+
+//certSecret returns the name of the secret the certificates of the webhook server behind svc are kept in
+func certSecret(svc utils.WebhookService) string {
+	return svc.Name + "-certs"
+}
+
+//BootstrapCertificates provides the certificates of the webhook server behind svc without cert-manager. The
+//certificates are kept in a secret next to the service, so restarts and replicas of the controller share
+//them, and are generated when the secret is missing or they are about to expire. The webhook configurations
+//are created or updated to trust the CA of the certificates.
+func BootstrapCertificates(ctx context.Context, kc kubernetes.Interface, svc utils.WebhookService) (*Certificates, error) {
+	secret, err := utils.GetSecret(ctx, kc, certSecret(svc), svc.Namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to read webhook certificates: %v", err)
+	}
+
+	var certs *Certificates
+	if err == nil {
+		certs = &Certificates{CA: secret.Data["ca.crt"], Cert: secret.Data[v1.TLSCertKey], Key: secret.Data[v1.TLSPrivateKeyKey]}
+	}
+	if certs == nil || !certs.valid(svc) {
+		certs, err = GenerateCertificates(svc)
+		if err != nil {
+			return nil, err
+		}
+		_, err = utils.SaveSecret(ctx, kc, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: certSecret(svc), Namespace: svc.Namespace},
+			Type:       v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				"ca.crt":            certs.CA,
+				v1.TLSCertKey:       certs.Cert,
+				v1.TLSPrivateKeyKey: certs.Key,
+			},
+		})
+		//a replica that started at the same time may have saved its certificates first
+		if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+			return BootstrapCertificates(ctx, kc, svc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save webhook certificates: %v", err)
+		}
+	}
+
+	err = utils.EnsureWebhookConfigurations(ctx, kc, svc, certs.CA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure webhooks: %v", err)
+	}
+	return certs, nil
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	wfv2 "github.com/arunprasadmudaliar/trinity/api/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+//conversionReview is the apiextensions.k8s.io/v1 ConversionReview the API server sends to convert custom
+//resources between versions. Only the fields the webhook uses are declared.
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+//convert answers the ConversionReviews of workflows
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var review conversionReview
+	err = json.Unmarshal(body, &review)
+	if err != nil || review.Request == nil {
+		s.log.WithError(err).Error("failed to decode conversion review")
+		http.Error(w, "expected a ConversionReview with a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &conversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range review.Request.Objects {
+		converted, err := Convert(obj.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			s.log.WithError(err).Errorf("failed to convert workflow to %s", review.Request.DesiredAPIVersion)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	review.Request = nil
+	review.Response = response
+
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+//Convert converts the JSON encoded workflow obj to apiVersion, which is trinity.cloudlego.com/v1 or v2
+func Convert(obj []byte, apiVersion string) ([]byte, error) {
+	var meta metav1.TypeMeta
+	err := json.Unmarshal(obj, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode object: %v", err)
+	}
+	if meta.APIVersion == apiVersion {
+		return obj, nil
+	}
+
+	//v1 is the version workflows are stored in, conversions between other versions go through it
+	hub := &wfv1.Workflow{}
+	switch meta.APIVersion {
+	case wfv1.GroupVersion.String():
+		err = json.Unmarshal(obj, hub)
+	case wfv2.GroupVersion.String():
+		wf := &wfv2.Workflow{}
+		err = json.Unmarshal(obj, wf)
+		if err == nil {
+			err = wf.ConvertTo(hub)
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert from apiVersion %q", meta.APIVersion)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s workflow: %v", meta.APIVersion, err)
+	}
+
+	switch apiVersion {
+	case wfv1.GroupVersion.String():
+		return json.Marshal(hub)
+	case wfv2.GroupVersion.String():
+		wf := &wfv2.Workflow{}
+		err = wf.ConvertFrom(hub)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(wf)
+	}
+	return nil, fmt.Errorf("cannot convert to apiVersion %q", apiVersion)
+}
@@ -0,0 +1,270 @@
+//Package webhook serves the admission webhooks that validate workflows and apply their defaults, and the
+//conversion webhook that converts workflows between the versions of the API
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	"github.com/arunprasadmudaliar/trinity/pkg/sdk"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+//conversionInterval is how often the conversion of the workflow CRD is restored when the controller manages it
+const conversionInterval = time.Minute
+
+//Options configure the webhook server
+type Options struct {
+	//Addr is the address the server listens on, :9443 when empty
+	Addr string
+	//Service is the service the API server reaches the webhook server through
+	Service utils.WebhookService
+	//CertDir holds tls.crt and tls.key of a certificate provided for the server, e.g. by cert-manager. The
+	//certificates are bootstrapped, and the webhook configurations and the conversion of the workflow CRD
+	//maintained, by the server when it is empty.
+	CertDir string
+}
+
+//Start serves the webhooks for the cluster described by config until ctx is cancelled
+func Start(ctx context.Context, config *rest.Config, opts Options, log logrus.FieldLogger) error {
+	log = utils.Logger(log)
+	if opts.Addr == "" {
+		opts.Addr = ":9443"
+	}
+
+	var certs *Certificates
+	if opts.CertDir != "" {
+		cert, err := ioutil.ReadFile(filepath.Join(opts.CertDir, v1.TLSCertKey))
+		if err != nil {
+			return err
+		}
+		key, err := ioutil.ReadFile(filepath.Join(opts.CertDir, v1.TLSPrivateKeyKey))
+		if err != nil {
+			return err
+		}
+		certs = &Certificates{Cert: cert, Key: key}
+	} else {
+		kc, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %v", err)
+		}
+		certs, err = BootstrapCertificates(ctx, kc, opts.Service)
+		if err != nil {
+			return err
+		}
+		dc, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to create dynamic client: %v", err)
+		}
+		err = utils.EnsureCRDConversion(ctx, dc, opts.Service, certs.CA)
+		if err != nil {
+			return fmt.Errorf("failed to configure conversion of %s: %v", utils.WorkflowCRD, err)
+		}
+		//the conversion is lost when the CRD is replaced, e.g. on an upgrade
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			err := utils.EnsureCRDConversion(ctx, dc, opts.Service, certs.CA)
+			if err != nil {
+				log.WithError(err).Errorf("Failed to configure conversion of %s", utils.WorkflowCRD)
+			}
+		}, conversionInterval)
+		log.Infof("webhook configurations %s and CRD %s trust the certificates of service %s/%s", utils.WebhookConfiguration, utils.WorkflowCRD, opts.Service.Namespace, opts.Service.Name)
+	}
+	return NewServer(log).Serve(ctx, opts.Addr, certs)
+}
+
+//Server handles the admission reviews of workflows
+type Server struct {
+	log logrus.FieldLogger
+}
+
+//NewServer creates a Server
+func NewServer(log logrus.FieldLogger) *Server {
+	return &Server{log: utils.Logger(log)}
+}
+
+//Handler returns the handler of the webhooks: /validate rejects invalid workflows, /mutate applies the
+//defaults of workflows and /convert converts workflows between v1 and v2
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/validate", s.review(s.validate))
+	mux.Handle("/mutate", s.review(s.mutate))
+	mux.HandleFunc("/convert", s.convert)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+//Serve serves the webhooks over TLS with certs until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, addr string, certs *Certificates) error {
+	cert, err := tls.X509KeyPair(certs.Cert, certs.Key)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook certificate: %v", err)
+	}
+	srv := &http.Server{
+		Addr:      addr,
+		Handler:   s.Handler(),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServeTLS("", "")
+	}()
+	s.log.Infof("serving webhooks on %s", addr)
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("webhook server stopped: %v", err)
+	case <-ctx.Done():
+		s.log.Info("Stopping webhook server...")
+		sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(sctx)
+	}
+}
+
+//review decodes the AdmissionReview of a request, answers it with the response of handle and writes it back
+func (s *Server) review(handle func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var review admissionv1.AdmissionReview
+		err = json.Unmarshal(body, &review)
+		if err != nil || review.Request == nil {
+			s.log.WithError(err).Error("failed to decode admission review")
+			http.Error(w, "expected an AdmissionReview with a request", http.StatusBadRequest)
+			return
+		}
+
+		response := handle(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		data, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+//decode reads the workflow of a request
+func decode(req *admissionv1.AdmissionRequest) (*wfv1.Workflow, *admissionv1.AdmissionResponse) {
+	wf := &wfv1.Workflow{}
+	err := json.Unmarshal(req.Object.Raw, wf)
+	if err != nil {
+		return nil, &admissionv1.AdmissionResponse{
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusBadRequest,
+				Reason:  metav1.StatusReasonBadRequest,
+				Message: fmt.Sprintf("failed to decode workflow: %v", err),
+			},
+		}
+	}
+	return wf, nil
+}
+
+//validate rejects workflows that sdk.Validate finds problems with, listing the problems
+func (s *Server) validate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	wf, response := decode(req)
+	if response != nil {
+		return response
+	}
+
+	//workflows stored before the webhook was installed can still be changed as long as the spec is kept
+	if req.Operation == admissionv1.Update {
+		old := &wfv1.Workflow{}
+		if json.Unmarshal(req.OldObject.Raw, old) == nil && equalJSON(old.Spec, wf.Spec) {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+	}
+
+	var errs field.ErrorList
+	if wf.Name == "" && wf.GenerateName != "" {
+		//the name is generated after admission
+		errs = sdk.ValidateSpec(&wf.Spec, field.NewPath("spec"))
+	} else {
+		errs = sdk.Validate(wf)
+	}
+	if len(errs) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	s.log.Infof("rejected %s of workflow %s/%s: %v", req.Operation, req.Namespace, wf.Name, errs.ToAggregate())
+	status := apierrors.NewInvalid(schema.GroupKind{Group: wfv1.GroupVersion.Group, Kind: wfv1.Kind}, wf.Name, errs).Status()
+	return &admissionv1.AdmissionResponse{Result: &status}
+}
+
+//mutate applies the defaults of workflows with a JSON patch that adds the fields of the spec that changed
+func (s *Server) mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	wf, response := decode(req)
+	if response != nil {
+		return response
+	}
+
+	defaulted := wf.DeepCopy()
+	wfv1.SetDefaults(defaulted)
+
+	var before, after map[string]interface{}
+	data, _ := json.Marshal(wf.Spec)
+	json.Unmarshal(data, &before)
+	data, _ = json.Marshal(defaulted.Spec)
+	json.Unmarshal(data, &after)
+
+	patch := []map[string]interface{}{}
+	var object map[string]interface{}
+	json.Unmarshal(req.Object.Raw, &object)
+	if _, ok := object["spec"]; !ok {
+		patch = append(patch, map[string]interface{}{"op": "add", "path": "/spec", "value": after})
+		before = after
+	}
+	for field, value := range after {
+		if old, ok := before[field]; ok && equalJSON(old, value) {
+			continue
+		}
+		//add replaces a member that is already set
+		patch = append(patch, map[string]interface{}{"op": "add", "path": "/spec/" + field, "value": value})
+	}
+	if len(patch) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}}
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{Allowed: true, Patch: data, PatchType: &patchType}
+}
+
+func equalJSON(a interface{}, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	wfv1 "github.com/arunprasadmudaliar/trinity/api/v1"
+	wfv2 "github.com/arunprasadmudaliar/trinity/api/v2"
+	"github.com/arunprasadmudaliar/trinity/pkg/utils"
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+//admit sends an admission review for wf to path and returns the response
+func admit(t *testing.T, path string, op admissionv1.Operation, wf interface{}, old interface{}) *admissionv1.AdmissionResponse {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	srv := httptest.NewServer(NewServer(log).Handler())
+	defer srv.Close()
+
+	raw, _ := json.Marshal(wf)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "uid-1",
+			Operation: op,
+			Namespace: "default",
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	if old != nil {
+		review.Request.OldObject.Raw, _ = json.Marshal(old)
+	}
+	body, _ := json.Marshal(review)
+
+	resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Response == nil || review.Response.UID != "uid-1" {
+		t.Fatalf("got response %+v, want a response to the request", review.Response)
+	}
+	return review.Response
+}
+
+func invalidWorkflow() *wfv1.Workflow {
+	wf := &wfv1.Workflow{}
+	wf.Name = "wf1"
+	wf.Spec.Schedule = "99 * * * *"
+	wf.Spec.Tasks = []wfv1.Workflowtask{{Name: "build"}, {Name: "build"}}
+	wf.Spec.Tasks[0].Command.Inline.Command = "make"
+	wf.Spec.Tasks[1].Command.Inline.Command = "make"
+	return wf
+}
+
+func TestValidateRejectsInvalidWorkflow(t *testing.T) {
+	response := admit(t, "/validate", admissionv1.Create, invalidWorkflow(), nil)
+
+	if response.Allowed {
+		t.Fatal("invalid workflow was allowed")
+	}
+	for _, want := range []string{"spec.schedule", "spec.tasks[1].name: Duplicate value"} {
+		if !strings.Contains(response.Result.Message, want) {
+			t.Errorf("got message %q, want it to mention %q", response.Result.Message, want)
+		}
+	}
+}
+
+func TestValidateAllowsWorkflowWithUnchangedSpec(t *testing.T) {
+	old := invalidWorkflow()
+	wf := invalidWorkflow()
+	wf.Labels = map[string]string{"team": "a"}
+
+	response := admit(t, "/validate", admissionv1.Update, wf, old)
+	if !response.Allowed {
+		t.Errorf("update of the labels of a stored workflow was rejected: %+v", response.Result)
+	}
+}
+
+func TestMutateAppliesDefaults(t *testing.T) {
+	wf := map[string]interface{}{
+		"apiVersion": "trinity.cloudlego.com/v1",
+		"kind":       "WorkFlow",
+		"metadata":   map[string]interface{}{"name": "wf1"},
+		"spec": map[string]interface{}{
+			"tasks": []interface{}{map[string]interface{}{"name": "hello", "command": map[string]interface{}{"script": "echo hello"}}},
+		},
+	}
+
+	response := admit(t, "/mutate", admissionv1.Create, wf, nil)
+	if !response.Allowed || response.PatchType == nil || *response.PatchType != admissionv1.PatchTypeJSONPatch {
+		t.Fatalf("got response %+v, want a JSON patch", response)
+	}
+	var patch []map[string]interface{}
+	err := json.Unmarshal(response.Patch, &patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	for _, op := range patch {
+		got[op["path"].(string)] = op["value"]
+	}
+	if got["/spec/schedule"] != wfv1.DefaultSchedule || got["/spec/outputLimit"] != float64(wfv1.DefaultOutputLimit) || len(got) != 2 {
+		t.Errorf("got patch %v, want the default schedule and output limit", got)
+	}
+}
+
+func TestBootstrapCertificates(t *testing.T) {
+	kc := kubefake.NewSimpleClientset()
+	svc := utils.WebhookService{Name: "trinity-webhook", Namespace: "default", Port: 443}
+	ctx := context.Background()
+
+	certs, err := BootstrapCertificates(ctx, kc, svc)
+	if err != nil {
+		t.Fatalf("BootstrapCertificates failed: %v", err)
+	}
+	if !certs.valid(svc) {
+		t.Errorf("generated certificates are not valid for %s", dnsNames(svc)[2])
+	}
+
+	validating, err := kc.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, utils.WebhookConfiguration, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("validating webhook configuration was not created: %v", err)
+	}
+	if !bytes.Equal(validating.Webhooks[0].ClientConfig.CABundle, certs.CA) {
+		t.Errorf("validating webhook configuration does not trust the CA of the certificates")
+	}
+	_, err = kc.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, utils.WebhookConfiguration, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("mutating webhook configuration was not created: %v", err)
+	}
+
+	again, err := BootstrapCertificates(ctx, kc, svc)
+	if err != nil {
+		t.Fatalf("BootstrapCertificates failed: %v", err)
+	}
+	if !bytes.Equal(again.CA, certs.CA) || !bytes.Equal(again.Cert, certs.Cert) {
+		t.Errorf("certificates were generated again instead of being read from the secret")
+	}
+}
+
+func TestConvertWorkflow(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	srv := httptest.NewServer(NewServer(log).Handler())
+	defer srv.Close()
+
+	wf := invalidWorkflow()
+	wf.APIVersion = wfv1.GroupVersion.String()
+	wf.Kind = "WorkFlow"
+	raw, _ := json.Marshal(wf)
+	review := conversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &conversionRequest{
+			UID:               "uid-1",
+			DesiredAPIVersion: wfv2.GroupVersion.String(),
+			Objects:           []runtime.RawExtension{{Raw: raw}},
+		},
+	}
+	body, _ := json.Marshal(review)
+
+	resp, err := http.Post(srv.URL+"/convert", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Response == nil || review.Response.UID != "uid-1" || review.Response.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("got response %+v, want a successful conversion", review.Response)
+	}
+	if len(review.Response.ConvertedObjects) != 1 {
+		t.Fatalf("got %d converted objects, want 1", len(review.Response.ConvertedObjects))
+	}
+
+	var converted wfv2.Workflow
+	err = json.Unmarshal(review.Response.ConvertedObjects[0].Raw, &converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.APIVersion != wfv2.GroupVersion.String() || converted.Kind != "WorkFlow" {
+		t.Errorf("got %s %s, want a v2 WorkFlow", converted.APIVersion, converted.Kind)
+	}
+	if inline := converted.Spec.Tasks[0].Command.Inline; inline == nil || inline.Command != "make" {
+		t.Errorf("got inline command %+v, want make", inline)
+	}
+
+	_, err = Convert(raw, "trinity.cloudlego.com/v3")
+	if err == nil {
+		t.Error("conversion to an unknown version succeeded")
+	}
+}